@@ -0,0 +1,73 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+)
+
+// chunk is a piece of lesson output along with the source line of the
+// lesson function that wrote it.
+type chunk struct {
+	line int
+	text string
+}
+
+// transcript is the output of one lesson run, in the order it was written.
+type transcript []chunk
+
+// lineWriter is an io.Writer that records each write with the line of the
+// lesson function fn it came from. Writes made from helpers and closures
+// are attributed to the line in fn that called them.
+type lineWriter struct {
+	fn     string
+	chunks transcript
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	line := 0
+	for {
+		f, more := frames.Next()
+		if f.Function == lw.fn {
+			line = f.Line
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	lw.chunks = append(lw.chunks, chunk{line: line, text: string(p)})
+	return len(p), nil
+}
+
+// captureLesson runs l and returns its output.
+func captureLesson(l Lesson) transcript {
+	if l.pack != nil {
+		return l.pack.capture()
+	}
+	lw := &lineWriter{fn: lessonFuncName(l)}
+	l.Run(lessonWriter(lw))
+	return lw.chunks
+}
+
+// String returns the whole output.
+func (t transcript) String() string {
+	var b strings.Builder
+	for _, c := range t {
+		b.WriteString(c.text)
+	}
+	return b.String()
+}
+
+// between returns the output written by the source lines in (from, to].
+func (t transcript) between(from, to int) string {
+	var b strings.Builder
+	for _, c := range t {
+		if c.line > from && c.line <= to {
+			b.WriteString(c.text)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is one gonutshell sub-command.
+type command struct {
+	name  string
+	args  string // synopsis of the arguments, shown in the usage text
+	short string
+	run   func(w io.Writer, args []string) error
+	packs bool // uses the lessons of packs, which are loaded for it
+}
+
+// commands is set up in init as 'help' needs to range over it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"list", "", "list the lessons of the tour", cmdList, true},
+		{"run", "[lesson...]", "run the given lessons, or the whole tour", cmdRun, true},
+		{"step", "<lesson> [step]", "go through a lesson one snippet at a time", cmdStep, true},
+		{"fmt", "<verb> <value> [-type type] | -matrix", "show how a fmt verb formats a value, or every verb on many", cmdFmt, false},
+		{"race", "[expr...]", "print tour expressions built with the race detector, by default counters shared by goroutines", cmdRace, false},
+		{"search", "[-n count] <query>", "find where the lessons explain something", cmdSearch, true},
+		{"curriculum", "", "list the lessons in order with their prerequisites", cmdCurriculum, true},
+		{"path", "<lesson|tag>", "show the lessons to go through to learn about a topic", cmdPath, true},
+		{"quiz", "[lesson...]", "predict the output of snippets of the lessons", cmdQuiz, true},
+		{"progress", "", "show what you have done so far", cmdProgress, true},
+		{"resume", "", "continue step mode where you stopped", cmdResume, true},
+		{"try", "[-allow-network] <lesson> <step>", "edit the code of a step and compare its output", cmdTry, true},
+		{"serve", "[-addr host:port] [-allow-network]", "serve the tour as a web playground", cmdServe, true},
+		{"export", "[-md file] [-html dir] [lesson...]", "write the tour as Markdown and/or a static HTML site", cmdExport, true},
+		{"verify", "[lesson...]", "check the '// Output:' comments against the real output", cmdVerify, true},
+		{"typecheck", "", "check the types stated in comments with go/types", cmdTypecheck, false},
+		{"coverage", "[-json]", "list the features of the Go spec the lessons cover, and those they do not", cmdCoverage, false},
+		{"gallery", "[snippet|lesson...]", "show code that does not compile, with the compiler's diagnostics", cmdGallery, false},
+		{"packs", "", "list the lesson packs loaded and the lessons they add", cmdPacks, true},
+		{"translations", "[-template] [-v] [lang...]", "check the translations of the narrative against the English", cmdTranslations, false},
+		{"help", "", "show this help", cmdHelp, false},
+	}
+}
+
+// globalFlags returns the flags that come before the sub-command, which
+// set the fields of o.
+func globalFlags(o *tourOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("gonutshell", flag.ContinueOnError)
+	fs.BoolVar(&o.deterministic, "deterministic", envDeterministic(),
+		"stable output: symbolic addresses and sorted maps (or set $"+deterministicEnv+")")
+	fs.BoolVar(&o.sortedMaps, "sorted-maps", false, "also show maps in key order")
+	fs.StringVar(&o.lang, "lang", envLang(), "language of the narrative: "+strings.Join(languages(), ", ")+" (or set $"+langEnv+")")
+	fs.StringVar(&o.packs, "packs", os.Getenv(packsEnv), "`dirs` to load lesson packs from, separated by '"+string(filepath.ListSeparator)+"' (or set $"+packsEnv+")")
+	return fs
+}
+
+// parseGlobalFlags parses the global flags into options, returning the
+// remaining arguments.
+func parseGlobalFlags(stderr io.Writer, args []string) ([]string, error) {
+	fs := globalFlags(&options)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { cmdHelp(stderr, nil) }
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := checkLang(options.lang); err != nil {
+		fmt.Fprintf(stderr, "gonutshell: %v\n", err)
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// runCommand dispatches args to a sub-command and returns the exit code.
+// Without any arguments the whole tour is run, as it always has been.
+func runCommand(stdout, stderr io.Writer, args []string) int {
+	args, err := parseGlobalFlags(stderr, args)
+	if err != nil {
+		return 2
+	}
+	if len(args) == 0 {
+		args = []string{"run"}
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		if c.packs {
+			loadPacks(stderr, packDirs())
+		}
+		if err := c.run(stdout, args[1:]); err != nil {
+			fmt.Fprintf(stderr, "gonutshell %s: %v\n", c.name, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "gonutshell: unknown command %q\n\n", args[0])
+	cmdHelp(stderr, nil)
+	return 2
+}
+
+func cmdHelp(w io.Writer, args []string) error {
+	fmt.Fprintln(w, "usage: gonutshell [-deterministic] [-sorted-maps] [-lang code] [-packs dirs] <command> [arguments]")
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "\t%s %s\t%s\n", c.name, c.args, c.short)
+	}
+	fmt.Fprintln(tw, "\noptions:")
+	globalFlags(new(tourOptions)).VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(tw, "\t-%s\t%s\n", f.Name, f.Usage)
+	})
+	return tw.Flush()
+}
+
+func cmdList(w io.Writer, args []string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, l := range lessons {
+		fmt.Fprintf(tw, "%s\t%s\n", l.ID, lessonTitle(l))
+	}
+	return tw.Flush()
+}
+
+func cmdRun(w io.Writer, args []string) error {
+	run, err := selectLessons(args)
+	if err != nil {
+		return err
+	}
+	for i, l := range run {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "==== %s ====\n", lessonTitle(l))
+		l.Run(lessonWriter(w))
+	}
+	return nil
+}
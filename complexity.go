@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+func lessonComplexity(w io.Writer) {
+	// ==== Complexity of deleting from a slice
+	/*
+		The Slices lesson says that deleting an element by moving the last
+		one into its place has constant time complexity, and that deleting
+		it by copying everything after it one place left has linear time
+		complexity. Let us measure it rather than take it on trust.
+	*/
+	idioms := []deleteIdiom{
+		{"swap with last", deleteSwap},
+		{"copy the tail", deleteCopy},
+		{"slices.Delete", deleteSlices},
+	}
+	sizes := []int{1_000, 10_000, 100_000, 1_000_000}
+
+	// --- timing with testing.Benchmark
+	timings := make([][]float64, len(idioms)) // ns per delete, by idiom and size
+	if options.deterministic {
+		fmt.Fprintln(w, "(the timings differ from run to run, using typical ones)")
+		timings = typicalTimings
+	} else {
+		for i, d := range idioms {
+			for _, n := range sizes {
+				r := testing.Benchmark(benchDelete(d.del, n))
+				timings[i] = append(timings[i], float64(max(r.NsPerOp(), 1)))
+			}
+		}
+	}
+	/*
+		testing.Benchmark runs a benchmark function outside of 'go test',
+		calling it with a growing b.N until the loop takes a second. Each
+		delete takes out the first element, the worst case for the copying
+		idioms, and then grows the slice back to n. NsPerOp is in whole
+		nanoseconds, so a delete faster than that counts as one.
+	*/
+
+	// --- fitting the timings to O(1) and O(n)
+	for i, d := range idioms {
+		fmt.Fprintf(w, "%-14s is %s\n", d.name, fitComplexity(sizes, timings[i]))
+	}
+	// e.g. copy the tail  is O(n), about 0.1ns per element
+	/*
+		A constant time delete takes as long for a million elements as
+		for a thousand, a linear one a thousand times longer. Both models
+		are fitted to the timings and the one that is off by less wins.
+		slices.Delete copies the tail too, so it is linear as well.
+	*/
+
+	// --- table and chart
+	printTimings(w, idioms, sizes, timings)
+	fmt.Fprintln(w)
+	chartTimings(w, idioms, sizes, timings)
+}
+
+// deleteIdiom is a way of deleting the element at index i of a slice.
+type deleteIdiom struct {
+	name string
+	del  func(s []int, i int) []int
+}
+
+// deleteSwap is the fast delete of the Slices lesson; it does not keep the
+// order of the elements.
+func deleteSwap(s []int, i int) []int {
+	s[i] = s[len(s)-1]
+	s[len(s)-1] = 0
+	return s[:len(s)-1]
+}
+
+// deleteCopy is the slow delete of the Slices lesson, keeping the order.
+func deleteCopy(s []int, i int) []int {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = 0
+	return s[:len(s)-1]
+}
+
+func deleteSlices(s []int, i int) []int {
+	return slices.Delete(s, i, i+1)
+}
+
+// benchDelete returns a benchmark of deleting the first element of a slice
+// of n elements with del.
+func benchDelete(del func([]int, int) []int, n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		s := make([]int, n)
+		b.ResetTimer()
+		for range b.N {
+			s = del(s, 0)[:n] // the capacity is still there
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestDeleteIdioms(t *testing.T) {
+	for _, tt := range []struct {
+		del  func([]int, int) []int
+		want []int
+	}{
+		{deleteSwap, []int{1, 5, 3, 4}},
+		{deleteCopy, []int{1, 3, 4, 5}},
+		{deleteSlices, []int{1, 3, 4, 5}},
+	} {
+		s := []int{1, 2, 3, 4, 5}
+		if got := tt.del(s, 1); !slices.Equal(got, tt.want) || s[4] != 0 {
+			t.Errorf("got %v, want %v and the last element zeroed: %v", got, tt.want, s)
+		}
+	}
+}
+
+func TestFitComplexity(t *testing.T) {
+	sizes := []int{1_000, 10_000, 100_000}
+	for _, tt := range []struct {
+		ns   []float64
+		want complexityFit
+	}{
+		{[]float64{3, 2.5, 3.5}, complexityFit{"O(1)", 2.96}},
+		{[]float64{500, 5200, 49000}, complexityFit{"O(n)", 0.5}},
+	} {
+		got := fitComplexity(sizes, tt.ns)
+		if got.class != tt.want.class || got.ns < tt.want.ns*0.95 || got.ns > tt.want.ns*1.05 {
+			t.Errorf("fitComplexity(%v) = %v, want %v", tt.ns, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkDelete times the delete idioms of the Slices lesson; with
+// 'go test -bench Delete' the nanoseconds per operation grow with n for
+// the copying idioms only.
+func BenchmarkDelete(b *testing.B) {
+	for _, d := range []deleteIdiom{
+		{"swap", deleteSwap},
+		{"copy", deleteCopy},
+		{"slices.Delete", deleteSlices},
+	} {
+		for _, n := range []int{1_000, 10_000, 100_000, 1_000_000} {
+			b.Run(fmt.Sprintf("%s/n=%d", d.name, n), benchDelete(d.del, n))
+		}
+	}
+}
+
+// quickBenchmarks has the testing.Benchmark calls of the Complexity lesson
+// run for a millisecond rather than the second of -test.benchtime, until
+// the end of t.
+func quickBenchmarks(t *testing.T) {
+	bt := flag.Lookup("test.benchtime")
+	old := bt.Value.String()
+	bt.Value.Set("1ms")
+	t.Cleanup(func() { bt.Value.Set(old) })
+}
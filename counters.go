@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+func lessonCounters(w io.Writer) {
+	// ==== Counters goroutines can share
+	/*
+		The counters counterFact returns in the First-class functions
+		lesson are closures that do init++ on each call. Two goroutines
+		calling one at the same time can both read the same init and both
+		write back init+1: one of the two increments is lost. That is a
+		data race, which the race detector of 'go run -race' and
+		'go test -race' reports when the program runs into one.
+	*/
+
+	// --- the race detector
+	fmt.Fprintln(w, "run 'gonutshell race' to see the race detector catch counterFact")
+	// Output: run 'gonutshell race' to see the race detector catch counterFact
+	/*
+		'gonutshell race' builds the tour with -race and has 8 goroutines
+		call counterFact's counter a thousand times each, then the counter
+		with a mutex below. The first can count to less than 8000, and
+		whether it does or not the race detector reports a read and a
+		previous write in counterFact.func1, the closure, both at init++.
+		The counter with a mutex counts to 8000 every time, without a
+		report. -race needs cgo, so a C compiler as well as Go.
+	*/
+
+	// --- a mutex & an atomic counter
+	mc, ac := newMutexCounter(0), newAtomicCounter(0)
+	fmt.Fprintln(w, "mutex counter:", hammer(mc.Next, 8, 1000))  // Output: mutex counter: 8000
+	fmt.Fprintln(w, "atomic counter:", hammer(ac.Next, 8, 1000)) // Output: atomic counter: 8000
+	/*
+		The mutexCounter does what the closure does, between Lock and
+		Unlock. The atomicCounter only counts the calls, with an
+		atomic.Int64 whose Add is a single instruction no goroutine can
+		come in the middle of, and works the value out from the count.
+		It is the faster of the two when many goroutines share a counter,
+		see 'go test -bench Counter'.
+	*/
+	var next counter = newAtomicCounter(100).Next // a method value is a func() int too
+	fmt.Fprintln(w, next(), next())               // Output: 101 102
+
+	// ==== Options, Reset & Snapshot
+	// --- step & limit
+	by5 := newMutexCounter(0, withStep(5), withLimit(12))
+	fmt.Fprintln(w, by5.Next(), by5.Next(), by5.Next(), by5.Next()) // Output: 5 10 12 12
+	// NOTE: past the limit the counter stays at it
+	wrap := newAtomicCounter(0, withStep(5), withLimit(12), resetAtLimit())
+	fmt.Fprintln(w, wrap.Next(), wrap.Next(), wrap.Next(), wrap.Next()) // Output: 5 10 5 10
+	/*
+		withStep, withLimit and resetAtLimit are functional options: each
+		returns a function that sets a field of the counterConfig the
+		constructors fill in, so a counter is made with the options it
+		needs in any order and the defaults for the rest.
+	*/
+
+	// --- Reset & Snapshot
+	fmt.Fprintf(w, "%+v\n", wrap.Snapshot()) // Output: {Value:10 Calls:4}
+	wrap.Reset()
+	fmt.Fprintf(w, "%+v\n", wrap.Snapshot()) // Output: {Value:0 Calls:0}
+	/*
+		A Snapshot is the value and the number of calls taken together,
+		under the lock or from a single atomic load, so it never has the
+		value of one call and the count of another.
+	*/
+}
+
+// safeCounter is a counter that goroutines may share.
+type safeCounter interface {
+	Next() int // the next value, as a call to a counter gives
+	Reset()    // back to the initial value
+	Snapshot() counterSnapshot
+}
+
+// counterSnapshot is the state of a safeCounter at one point in time.
+type counterSnapshot struct {
+	Value int // returned by the last call to Next, the initial value before it
+	Calls int // to Next since the counter was made or reset
+}
+
+// counterConfig is how a safeCounter counts.
+type counterConfig struct {
+	init, step int
+	limit      int
+	limited    bool
+	reset      bool // past the limit, start over from init
+}
+
+// counterOption sets an option of a safeCounter.
+type counterOption func(*counterConfig)
+
+// withStep makes a counter count by step rather than 1.
+func withStep(step int) counterOption {
+	return func(c *counterConfig) { c.step = step }
+}
+
+// withLimit makes a counter stop at limit.
+func withLimit(limit int) counterOption {
+	return func(c *counterConfig) { c.limit, c.limited = limit, true }
+}
+
+// resetAtLimit makes a counter with a limit start over from its initial
+// value rather than stop at the limit.
+func resetAtLimit() counterOption {
+	return func(c *counterConfig) { c.reset = true }
+}
+
+// newCounterConfig returns the configuration of a counter from init with
+// opts. It panics on options no counter can count with.
+func newCounterConfig(init int, opts []counterOption) counterConfig {
+	c := counterConfig{init: init, step: 1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.step <= 0:
+		panic(fmt.Sprintf("counter step %d is not positive", c.step))
+	case c.reset && !c.limited:
+		panic("counter resets at a limit it does not have")
+	case c.reset && c.limit < init+c.step:
+		panic(fmt.Sprintf("counter limit %d is reached before counting from %d", c.limit, init))
+	}
+	return c
+}
+
+// value returns the value of the counter after calls calls to Next.
+func (c counterConfig) value(calls int) int {
+	v := c.init + c.step*calls
+	if !c.limited || v <= c.limit {
+		return v
+	}
+	if !c.reset {
+		return c.limit
+	}
+	round := (c.limit - c.init) / c.step // values up to the limit
+	return c.init + c.step*((calls-1)%round+1)
+}
+
+// mutexCounter is a safeCounter that guards its state with a mutex.
+type mutexCounter struct {
+	cfg   counterConfig
+	mu    sync.Mutex
+	value int
+	calls int
+}
+
+// newMutexCounter returns a mutexCounter counting from init.
+func newMutexCounter(init int, opts ...counterOption) *mutexCounter {
+	return &mutexCounter{cfg: newCounterConfig(init, opts), value: init}
+}
+
+func (c *mutexCounter) Next() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	c.value += c.cfg.step
+	if c.cfg.limited && c.value > c.cfg.limit {
+		if c.cfg.reset {
+			c.value = c.cfg.init + c.cfg.step
+		} else {
+			c.value = c.cfg.limit
+		}
+	}
+	return c.value
+}
+
+func (c *mutexCounter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value, c.calls = c.cfg.init, 0
+}
+
+func (c *mutexCounter) Snapshot() counterSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return counterSnapshot{Value: c.value, Calls: c.calls}
+}
+
+// atomicCounter is a safeCounter that only keeps the number of calls, in
+// an atomic integer, and works its value out from them.
+type atomicCounter struct {
+	cfg   counterConfig
+	calls atomic.Int64
+}
+
+// newAtomicCounter returns an atomicCounter counting from init.
+func newAtomicCounter(init int, opts ...counterOption) *atomicCounter {
+	return &atomicCounter{cfg: newCounterConfig(init, opts)}
+}
+
+func (c *atomicCounter) Next() int {
+	return c.cfg.value(int(c.calls.Add(1)))
+}
+
+func (c *atomicCounter) Reset() {
+	c.calls.Store(0)
+}
+
+func (c *atomicCounter) Snapshot() counterSnapshot {
+	calls := int(c.calls.Load())
+	return counterSnapshot{Value: c.cfg.value(calls), Calls: calls}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// counterKinds are the constructors of the safeCounters, by name.
+var counterKinds = []struct {
+	name string
+	make func(init int, opts ...counterOption) safeCounter
+}{
+	{"mutex", func(init int, opts ...counterOption) safeCounter { return newMutexCounter(init, opts...) }},
+	{"atomic", func(init int, opts ...counterOption) safeCounter { return newAtomicCounter(init, opts...) }},
+}
+
+func TestCounterOptions(t *testing.T) {
+	for _, tt := range []struct {
+		init int
+		opts []counterOption
+		want []int
+	}{
+		{0, nil, []int{1, 2, 3, 4}},
+		{100, []counterOption{withStep(10)}, []int{110, 120, 130, 140}},
+		{0, []counterOption{withLimit(3)}, []int{1, 2, 3, 3, 3}},
+		{0, []counterOption{withStep(5), withLimit(12)}, []int{5, 10, 12, 12}},
+		{0, []counterOption{resetAtLimit(), withLimit(3)}, []int{1, 2, 3, 1, 2, 3, 1}},
+		{1, []counterOption{withStep(2), withLimit(6), resetAtLimit()}, []int{3, 5, 3, 5}},
+		{0, []counterOption{withStep(5), withLimit(10), resetAtLimit()}, []int{5, 10, 5, 10, 5}},
+	} {
+		for _, k := range counterKinds {
+			c := k.make(tt.init, tt.opts...)
+			var got []int
+			for range tt.want {
+				got = append(got, c.Next())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s counter from %d counts %v, want %v", k.name, tt.init, got, tt.want)
+			}
+			want := counterSnapshot{Value: tt.want[len(tt.want)-1], Calls: len(tt.want)}
+			if s := c.Snapshot(); s != want {
+				t.Errorf("%s counter from %d: snapshot %+v, want %+v", k.name, tt.init, s, want)
+			}
+			c.Reset()
+			if s := c.Snapshot(); s != (counterSnapshot{Value: tt.init}) {
+				t.Errorf("%s counter from %d: snapshot after Reset %+v", k.name, tt.init, s)
+			}
+			if got := c.Next(); got != tt.want[0] {
+				t.Errorf("%s counter from %d: %d after Reset, want %d", k.name, tt.init, got, tt.want[0])
+			}
+		}
+	}
+}
+
+func TestCounterConfigPanics(t *testing.T) {
+	for _, opts := range [][]counterOption{
+		{withStep(0)},
+		{withStep(-1)},
+		{resetAtLimit()},
+		{withStep(5), withLimit(4), resetAtLimit()},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("a counter with %d options did not panic", len(opts))
+				}
+			}()
+			newCounterConfig(0, opts)
+		}()
+	}
+}
+
+// TestCountersConcurrently is for 'go test -race', which would report a
+// race in the counters.
+func TestCountersConcurrently(t *testing.T) {
+	for _, k := range counterKinds {
+		c := k.make(0)
+		if got := hammer(c.Next, 8, 1000); got != 8000 {
+			t.Errorf("%s counter counted to %d, want 8000", k.name, got)
+		}
+		if s := c.Snapshot(); s.Calls != 8000 {
+			t.Errorf("%s counter was called %d times, want 8000", k.name, s.Calls)
+		}
+		done := make(chan counterSnapshot)
+		go func() {
+			// a snapshot taken while counting has a value that goes with its calls
+			for range 100 {
+				if s := c.Snapshot(); s.Value != s.Calls {
+					done <- s
+					return
+				}
+			}
+			done <- counterSnapshot{}
+		}()
+		c.Reset()
+		hammer(c.Next, 4, 100)
+		if s := <-done; s != (counterSnapshot{}) {
+			t.Errorf("%s counter: snapshot %+v", k.name, s)
+		}
+	}
+}
+
+// BenchmarkCounter compares the counters with 'go test -bench Counter',
+// used by one goroutine and shared by more: the atomic one is the faster,
+// as its goroutines never wait for a lock.
+func BenchmarkCounter(b *testing.B) {
+	for _, k := range counterKinds {
+		b.Run(k.name+"/serial", func(b *testing.B) {
+			c := k.make(0)
+			for range b.N {
+				c.Next()
+			}
+		})
+		for _, p := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("%s/parallel-%dxGOMAXPROCS", k.name, p), func(b *testing.B) {
+				c := k.make(0)
+				b.SetParallelism(p)
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						c.Next()
+					}
+				})
+			})
+		}
+	}
+}
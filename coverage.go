@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// specURL is where the sections of the Go spec are, by anchor.
+const specURL = "https://go.dev/ref/spec#"
+
+// specFeature is a construct of the language the lessons may exercise,
+// along with the section of the spec that defines it.
+type specFeature struct {
+	Name    string
+	Section string // anchor in the spec
+	match   func(n ast.Node, info *types.Info) bool
+}
+
+// specFeatures are the constructs the coverage report looks for, in the
+// order of the spec.
+var specFeatures = []specFeature{
+	{"Rune literals", "Rune_literals", isLit(token.CHAR)},
+	{"Raw string literals", "String_literals", func(n ast.Node, _ *types.Info) bool {
+		lit, ok := n.(*ast.BasicLit)
+		return ok && lit.Kind == token.STRING && strings.HasPrefix(lit.Value, "`")
+	}},
+	{"Imaginary literals", "Imaginary_literals", isLit(token.IMAG)},
+	{"Array types", "Array_types", func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.ArrayType)
+		return ok && a.Len != nil
+	}},
+	{"Slice types", "Slice_types", func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.ArrayType)
+		return ok && a.Len == nil
+	}},
+	{"Struct types", "Struct_types", isNode[*ast.StructType]},
+	{"Embedded fields", "Struct_types", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.StructType)
+		return ok && slices.ContainsFunc(s.Fields.List, func(f *ast.Field) bool { return f.Names == nil })
+	}},
+	{"Pointer types", "Pointer_types", func(n ast.Node, info *types.Info) bool {
+		s, ok := n.(*ast.StarExpr)
+		return ok && info.Types[s].IsType()
+	}},
+	{"Function types", "Function_types", hasFuncType},
+	{"Variadic functions", "Function_types", func(n ast.Node, _ *types.Info) bool {
+		f, ok := n.(*ast.FuncType)
+		if !ok || f.Params.NumFields() == 0 {
+			return false
+		}
+		_, ok = f.Params.List[len(f.Params.List)-1].Type.(*ast.Ellipsis)
+		return ok
+	}},
+	{"Interface types", "Interface_types", isNode[*ast.InterfaceType]},
+	{"Map types", "Map_types", isNode[*ast.MapType]},
+	{"Channel types", "Channel_types", isNode[*ast.ChanType]},
+	{"Blank identifier", "Blank_identifier", func(n ast.Node, _ *types.Info) bool {
+		id, ok := n.(*ast.Ident)
+		return ok && id.Name == "_"
+	}},
+	{"Constant declarations", "Constant_declarations", isDecl(token.CONST)},
+	{"Iota", "Iota", func(n ast.Node, info *types.Info) bool {
+		id, ok := n.(*ast.Ident)
+		return ok && info.Uses[id] == types.Universe.Lookup("iota")
+	}},
+	{"Type definitions", "Type_definitions", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.TypeSpec)
+		return ok && !s.Assign.IsValid()
+	}},
+	{"Alias declarations", "Alias_declarations", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.TypeSpec)
+		return ok && s.Assign.IsValid()
+	}},
+	{"Type parameters", "Type_parameter_declarations", func(n ast.Node, _ *types.Info) bool {
+		switch n := n.(type) {
+		case *ast.FuncType:
+			return n.TypeParams != nil
+		case *ast.TypeSpec:
+			return n.TypeParams != nil
+		}
+		return false
+	}},
+	{"Variable declarations", "Variable_declarations", isDecl(token.VAR)},
+	{"Short variable declarations", "Short_variable_declarations", isAssign(token.DEFINE)},
+	{"Function declarations", "Function_declarations", func(n ast.Node, _ *types.Info) bool {
+		f, ok := n.(*ast.FuncDecl)
+		return ok && f.Recv == nil
+	}},
+	{"Method declarations", "Method_declarations", func(n ast.Node, _ *types.Info) bool {
+		f, ok := n.(*ast.FuncDecl)
+		return ok && f.Recv != nil
+	}},
+	{"Composite literals", "Composite_literals", isNode[*ast.CompositeLit]},
+	{"Function literals", "Function_literals", isNode[*ast.FuncLit]},
+	{"Index expressions", "Index_expressions", func(n ast.Node, info *types.Info) bool {
+		ix, ok := n.(*ast.IndexExpr)
+		if !ok {
+			return false
+		}
+		_, inst := info.Instances[indexedIdent(ix.X)]
+		return !inst
+	}},
+	{"Slice expressions", "Simple_slice_expressions", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.SliceExpr)
+		return ok && !s.Slice3
+	}},
+	{"Full slice expressions", "Full_slice_expressions", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.SliceExpr)
+		return ok && s.Slice3
+	}},
+	{"Type assertions", "Type_assertions", func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.TypeAssertExpr)
+		return ok && a.Type != nil
+	}},
+	{"Multiple results", "Return_statements", func(n ast.Node, _ *types.Info) bool {
+		f, ok := n.(*ast.FuncType)
+		return ok && f.Results.NumFields() > 1
+	}},
+	{"Named results", "Function_types", func(n ast.Node, _ *types.Info) bool {
+		f, ok := n.(*ast.FuncType)
+		return ok && f.Results != nil && f.Results.List[0].Names != nil
+	}},
+	{"Variadic calls with s...", "Passing_arguments_to_..._parameters", func(n ast.Node, _ *types.Info) bool {
+		c, ok := n.(*ast.CallExpr)
+		return ok && c.Ellipsis.IsValid()
+	}},
+	{"Instantiations", "Instantiations", func(n ast.Node, info *types.Info) bool {
+		id, ok := n.(*ast.Ident)
+		_, inst := info.Instances[id]
+		return ok && inst
+	}},
+	{"Address operators", "Address_operators", func(n ast.Node, info *types.Info) bool {
+		switch n := n.(type) {
+		case *ast.UnaryExpr:
+			return n.Op == token.AND
+		case *ast.StarExpr:
+			return !info.Types[n].IsType()
+		}
+		return false
+	}},
+	{"Receive operator", "Receive_operator", func(n ast.Node, _ *types.Info) bool {
+		u, ok := n.(*ast.UnaryExpr)
+		return ok && u.Op == token.ARROW
+	}},
+	{"Conversions", "Conversions", func(n ast.Node, info *types.Info) bool {
+		c, ok := n.(*ast.CallExpr)
+		return ok && info.Types[c.Fun].IsType()
+	}},
+	{"Send statements", "Send_statements", isNode[*ast.SendStmt]},
+	{"Increment and decrement", "IncDec_statements", isNode[*ast.IncDecStmt]},
+	{"Assignment operations", "Assignment_statements", func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.AssignStmt)
+		return ok && a.Tok != token.ASSIGN && a.Tok != token.DEFINE
+	}},
+	{"Tuple assignments", "Assignment_statements", func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.AssignStmt)
+		return ok && a.Tok == token.ASSIGN && len(a.Lhs) > 1
+	}},
+	{"If statements", "If_statements", isNode[*ast.IfStmt]},
+	{"If with a short statement", "If_statements", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.IfStmt)
+		return ok && s.Init != nil
+	}},
+	{"Expression switches", "Expression_switches", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.SwitchStmt)
+		return ok && s.Tag != nil
+	}},
+	{"Switch without a tag", "Expression_switches", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.SwitchStmt)
+		return ok && s.Tag == nil
+	}},
+	{"Type switches", "Type_switches", isNode[*ast.TypeSwitchStmt]},
+	{"Fallthrough", "Fallthrough_statements", isBranch(token.FALLTHROUGH)},
+	{"For with a condition", "For_statements_with_single_condition", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.ForStmt)
+		return ok && s.Init == nil && s.Post == nil && s.Cond != nil
+	}},
+	{"For with a for clause", "For_statements_with_for_clause", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.ForStmt)
+		return ok && (s.Init != nil || s.Post != nil)
+	}},
+	{"Infinite for loops", "For_statements", func(n ast.Node, _ *types.Info) bool {
+		s, ok := n.(*ast.ForStmt)
+		return ok && s.Init == nil && s.Post == nil && s.Cond == nil
+	}},
+	{"For with a range clause", "For_statements_with_range_clause", isNode[*ast.RangeStmt]},
+	{"Range over integers", "For_statements_with_range_clause", isRangeOver(func(t types.Type) bool {
+		b, ok := t.(*types.Basic)
+		return ok && b.Info()&types.IsInteger != 0
+	})},
+	{"Range over functions", "For_statements_with_range_clause", isRangeOver(func(t types.Type) bool {
+		_, ok := t.(*types.Signature)
+		return ok
+	})},
+	{"Go statements", "Go_statements", isNode[*ast.GoStmt]},
+	{"Select statements", "Select_statements", isNode[*ast.SelectStmt]},
+	{"Labeled statements", "Labeled_statements", isNode[*ast.LabeledStmt]},
+	{"Break statements", "Break_statements", isBranch(token.BREAK)},
+	{"Continue statements", "Continue_statements", isBranch(token.CONTINUE)},
+	{"Goto statements", "Goto_statements", isBranch(token.GOTO)},
+	{"Defer statements", "Defer_statements", isNode[*ast.DeferStmt]},
+	{"append and copy", "Appending_and_copying_slices", isBuiltinCall("append", "copy")},
+	{"clear", "Clear", isBuiltinCall("clear")},
+	{"close", "Close", isBuiltinCall("close")},
+	{"complex, real and imag", "Manipulating_complex_numbers", isBuiltinCall("complex", "real", "imag")},
+	{"delete", "Deletion_of_map_elements", isBuiltinCall("delete")},
+	{"len and cap", "Length_and_capacity", isBuiltinCall("len", "cap")},
+	{"make", "Making_slices_maps_and_channels", isBuiltinCall("make")},
+	{"min and max", "Min_and_max", isBuiltinCall("min", "max")},
+	{"new", "Allocation", isBuiltinCall("new")},
+	{"panic and recover", "Handling_panics", isBuiltinCall("panic", "recover")},
+}
+
+func isNode[T ast.Node](n ast.Node, _ *types.Info) bool {
+	_, ok := n.(T)
+	return ok
+}
+
+func isLit(kind token.Token) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, _ *types.Info) bool {
+		lit, ok := n.(*ast.BasicLit)
+		return ok && lit.Kind == kind
+	}
+}
+
+func isDecl(tok token.Token) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, _ *types.Info) bool {
+		d, ok := n.(*ast.GenDecl)
+		return ok && d.Tok == tok
+	}
+}
+
+func isAssign(tok token.Token) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, _ *types.Info) bool {
+		a, ok := n.(*ast.AssignStmt)
+		return ok && a.Tok == tok
+	}
+}
+
+func isBranch(tok token.Token) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, _ *types.Info) bool {
+		b, ok := n.(*ast.BranchStmt)
+		return ok && b.Tok == tok
+	}
+}
+
+func isRangeOver(match func(types.Type) bool) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, info *types.Info) bool {
+		r, ok := n.(*ast.RangeStmt)
+		return ok && r.X != nil && info.TypeOf(r.X) != nil && match(info.TypeOf(r.X).Underlying())
+	}
+}
+
+// isBuiltinCall matches calls of the built-in functions names.
+func isBuiltinCall(names ...string) func(ast.Node, *types.Info) bool {
+	return func(n ast.Node, info *types.Info) bool {
+		c, ok := n.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := ast.Unparen(c.Fun).(*ast.Ident)
+		if !ok {
+			return false
+		}
+		b, ok := info.Uses[id].(*types.Builtin)
+		return ok && slices.Contains(names, b.Name())
+	}
+}
+
+// hasFuncType matches the places a function type is written out, rather
+// than being part of a function declaration or literal.
+func hasFuncType(n ast.Node, _ *types.Info) bool {
+	var t ast.Expr
+	switch n := n.(type) {
+	case *ast.Field:
+		t = n.Type
+	case *ast.TypeSpec:
+		t = n.Type
+	case *ast.ValueSpec:
+		t = n.Type
+	case *ast.ArrayType:
+		t = n.Elt
+	case *ast.MapType:
+		t = n.Value
+	}
+	_, ok := t.(*ast.FuncType)
+	return ok
+}
+
+// indexedIdent returns the identifier x names, as in f or pkg.F, if any.
+func indexedIdent(x ast.Expr) *ast.Ident {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x
+	case *ast.SelectorExpr:
+		return x.Sel
+	}
+	return nil
+}
+
+// featureCoverage is a feature of the spec along with the lessons that
+// exercise it.
+type featureCoverage struct {
+	specFeature
+	Lessons []string
+}
+
+// specCoverage finds the features of the spec that the lessons ls exercise.
+// It looks at the code of the lessons and at the declarations of the tour
+// files they use, such as the example functions of the Functions lesson or
+// the counters of the Counters lesson, and those these use in turn. The
+// tooling of the lessons, in toolingFile, does not count.
+func specCoverage(t *tourSource, ls []Lesson) ([]featureCoverage, error) {
+	_, info, _ := typeCheck(t.fset, t.files)
+	examples := map[types.Object]ast.Node{}
+	for _, f := range t.files {
+		if t.fset.File(f.Pos()).Name() == toolingFile {
+			continue
+		}
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				examples[info.Defs[d.Name]] = d
+			case *ast.GenDecl:
+				for _, s := range d.Specs {
+					switch s := s.(type) {
+					case *ast.TypeSpec:
+						examples[info.Defs[s.Name]] = d
+					case *ast.ValueSpec:
+						for _, id := range s.Names {
+							examples[info.Defs[id]] = d
+						}
+					}
+				}
+			}
+		}
+	}
+
+	cov := make([]featureCoverage, len(specFeatures))
+	for i, f := range specFeatures {
+		cov[i].specFeature = f
+	}
+	type visit struct {
+		node   ast.Node
+		lesson string
+	}
+	seen := map[visit]bool{}
+	var walk func(root ast.Node, lesson string)
+	walk = func(root ast.Node, lesson string) {
+		if seen[visit{root, lesson}] {
+			return
+		}
+		seen[visit{root, lesson}] = true
+		ast.Inspect(root, func(n ast.Node) bool {
+			if n == nil {
+				return false
+			}
+			for i := range cov {
+				if cov[i].match(n, info) && !slices.Contains(cov[i].Lessons, lesson) {
+					cov[i].Lessons = append(cov[i].Lessons, lesson)
+				}
+			}
+			if id, ok := n.(*ast.Ident); ok {
+				if d, ok := examples[info.Uses[id]]; ok {
+					walk(d, lesson)
+				}
+			}
+			return true
+		})
+	}
+	for _, l := range ls {
+		fd, _, err := t.lessonDecl(l)
+		if err != nil {
+			return nil, err
+		}
+		walk(fd.Body, l.ID)
+	}
+	return cov, nil
+}
+
+// coverageReport is the JSON form of the coverage report.
+type coverageReport struct {
+	Covered   []coverageEntry `json:"covered"`
+	Uncovered []coverageEntry `json:"uncovered"`
+}
+
+type coverageEntry struct {
+	Feature string   `json:"feature"`
+	Section string   `json:"section"`
+	URL     string   `json:"url"`
+	Lessons []string `json:"lessons,omitempty"`
+}
+
+func newCoverageReport(cov []featureCoverage) coverageReport {
+	r := coverageReport{Covered: []coverageEntry{}, Uncovered: []coverageEntry{}}
+	for _, c := range cov {
+		e := coverageEntry{c.Name, strings.ReplaceAll(c.Section, "_", " "), specURL + c.Section, c.Lessons}
+		if len(c.Lessons) > 0 {
+			r.Covered = append(r.Covered, e)
+		} else {
+			r.Uncovered = append(r.Uncovered, e)
+		}
+	}
+	return r
+}
+
+func cmdCoverage(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	fs.SetOutput(w)
+	asJSON := fs.Bool("json", false, "write the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := loadTour()
+	if err != nil {
+		return err
+	}
+	cov, err := specCoverage(t, tourLessons(lessons))
+	if err != nil {
+		return err
+	}
+	r := newCoverageReport(cov)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Covered, %d of %d features:\n", len(r.Covered), len(cov))
+	for _, e := range r.Covered {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", e.Feature, e.Section, strings.Join(e.Lessons, ", "))
+	}
+	fmt.Fprintf(tw, "\nNot covered yet, %d features:\n", len(r.Uncovered))
+	for _, e := range r.Uncovered {
+		fmt.Fprintf(tw, "  %s\t%s\n", e.Feature, e.URL)
+	}
+	return tw.Flush()
+}
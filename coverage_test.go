@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestSpecCoverage(t *testing.T) {
+	// the lesson is looked up by the name of its function, which is that
+	// of a real lesson
+	src := `package main
+
+import "io"
+
+func lessonVariables(w io.Writer) {
+	s := []int{1, 2, 3}
+	for i := range 3 {
+		s[i] = double(s[i])
+	}
+	show(s)
+	switch {
+	case len(s) > 2:
+		defer println(s)
+	}
+}
+
+type point struct{ x, y int }
+
+func unused(ch chan int) { go func() { ch <- 1 }() }
+`
+	// an example declared in another tour file counts too, the tooling
+	// the lesson calls does not
+	src2 := `package main
+
+func double[T ~int](x T) T { return x * 2 }
+`
+	tooling := `package main
+
+func show(s []int) {
+	for {
+		select {}
+	}
+}
+`
+	fset := token.NewFileSet()
+	tour := &tourSource{fset: fset}
+	for name, src := range map[string]string{"tour1.go": src, "tour2.go": src2, toolingFile: tooling} {
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tour.files = append(tour.files, f)
+	}
+	cov, err := specCoverage(tour, []Lesson{{ID: "x", Run: lessonVariables}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var covered, uncovered []string
+	for _, c := range cov {
+		if len(c.Lessons) > 0 {
+			covered = append(covered, c.Name)
+		} else {
+			uncovered = append(uncovered, c.Name)
+		}
+	}
+	got := strings.Join(covered, ", ")
+	want := "Slice types, Type parameters, Short variable declarations, Function declarations, Composite literals, " +
+		"Index expressions, Instantiations, Switch without a tag, For with a range clause, Range over integers, " +
+		"Defer statements, len and cap"
+	if got != want {
+		t.Errorf("covered\n%s\nwant\n%s", got, want)
+	}
+	for _, name := range []string{"Struct types", "Go statements", "Channel types", "Send statements"} {
+		if !strings.Contains(strings.Join(uncovered, ", "), name) {
+			t.Errorf("%s is covered by declarations no lesson uses", name)
+		}
+	}
+	for _, name := range []string{"Infinite for loops", "Select statements"} {
+		if !strings.Contains(strings.Join(uncovered, ", "), name) {
+			t.Errorf("%s is covered by the tooling of the lesson", name)
+		}
+	}
+}
+
+func TestCoverageJSON(t *testing.T) {
+	var b strings.Builder
+	if err := cmdCoverage(&b, []string{"-json"}); err != nil {
+		t.Fatal(err)
+	}
+	var r coverageReport
+	if err := json.Unmarshal([]byte(b.String()), &r); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Covered) + len(r.Uncovered); n != len(specFeatures) {
+		t.Errorf("report has %d features, want %d", n, len(specFeatures))
+	}
+	for _, e := range r.Covered {
+		if e.Feature == "Composite literals" && !strings.HasPrefix(e.URL, specURL) {
+			t.Errorf("composite literals link to %s", e.URL)
+		}
+	}
+}
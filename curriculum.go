@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// curriculum orders ls so that every lesson comes after the lessons it
+// requires, keeping the order of ls wherever that leaves a choice. It fails
+// when a lesson requires one that is not in ls or when the prerequisites
+// form a cycle.
+func curriculum(ls []Lesson) ([]Lesson, error) {
+	index := map[string]int{}
+	for i, l := range ls {
+		index[l.ID] = i
+	}
+	for _, l := range ls {
+		for _, r := range l.Requires {
+			if _, ok := index[r]; !ok {
+				return nil, fmt.Errorf("lesson %s requires %q, which is not a lesson", l.ID, r)
+			}
+		}
+	}
+
+	// a depth-first walk putting the prerequisites of a lesson before it;
+	// coming back to a lesson still on the path means there is a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(ls))
+	var order []Lesson
+	var path []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, ls[i].ID):], ls[i].ID)
+			return fmt.Errorf("the prerequisites of the lessons form a cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[i] = visiting
+		path = append(path, ls[i].ID)
+		for _, r := range ls[i].Requires {
+			if err := visit(index[r]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		order = append(order, ls[i])
+		return nil
+	}
+	for i := range ls {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// pathTo returns the lessons of ls to go through, in order, to learn about
+// topic: the lesson with that ID, or else the lessons with that tag, after
+// all the lessons they require.
+func pathTo(ls []Lesson, topic string) ([]Lesson, error) {
+	var targets []Lesson
+	if l, ok := findIn(ls, topic); ok {
+		targets = []Lesson{l}
+	} else {
+		for _, l := range ls {
+			if slices.Contains(l.Tags, topic) {
+				targets = append(targets, l)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no lesson or tag %q, see 'gonutshell curriculum'", topic)
+	}
+	order, err := curriculum(ls)
+	if err != nil {
+		return nil, err
+	}
+	need := map[string]bool{}
+	var require func(l Lesson)
+	require = func(l Lesson) {
+		if need[l.ID] {
+			return
+		}
+		need[l.ID] = true
+		for _, r := range l.Requires {
+			rl, _ := findIn(ls, r)
+			require(rl)
+		}
+	}
+	for _, l := range targets {
+		require(l)
+	}
+	return slices.DeleteFunc(order, func(l Lesson) bool { return !need[l.ID] }), nil
+}
+
+// findIn looks up a lesson in ls by its ID.
+func findIn(ls []Lesson, id string) (Lesson, bool) {
+	i := slices.IndexFunc(ls, func(l Lesson) bool { return l.ID == id })
+	if i < 0 {
+		return Lesson{}, false
+	}
+	return ls[i], true
+}
+
+// missing returns the IDs of the lessons l builds on, directly or not, that
+// have not been completed yet.
+func (p *progress) missing(l Lesson) []string {
+	path, err := pathTo(lessons, l.ID)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, r := range path {
+		if lp, ok := p.Lessons[r.ID]; r.ID != l.ID && (!ok || lp.Completed.IsZero()) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// warnJumpAhead tells the learner about the prerequisites of l they have
+// not been through.
+func warnJumpAhead(w io.Writer, p *progress, l Lesson) {
+	if miss := p.missing(l); len(miss) > 0 {
+		fmt.Fprintf(w, "Heads up: %s builds on %s, which you have not completed yet.\n", l.ID, strings.Join(miss, ", "))
+		fmt.Fprintf(w, "See 'gonutshell path %s' for the lessons to go through first.\n", l.ID)
+	}
+}
+
+func cmdCurriculum(w io.Writer, args []string) error {
+	order, err := curriculum(lessons)
+	if err != nil {
+		return err
+	}
+	return printLessonTable(w, order)
+}
+
+func cmdPath(w io.Writer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: gonutshell path <lesson|tag>")
+	}
+	path, err := pathTo(lessons, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Path to %s:\n\n", args[0])
+	return printLessonTable(w, path)
+}
+
+// printLessonTable lists ls with their metadata, what the learner has
+// completed of them and how long the rest takes.
+func printLessonTable(w io.Writer, ls []Lesson) error {
+	p, err := loadProgress()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tlesson\t\tdifficulty\ttime\trequires\ttags")
+	left := 0
+	for i, l := range ls {
+		status := ""
+		if lp, ok := p.Lessons[l.ID]; ok && !lp.Completed.IsZero() {
+			status = "done"
+		} else {
+			left += l.Minutes
+		}
+		requires := "-"
+		if len(l.Requires) > 0 {
+			requires = strings.Join(l.Requires, ", ")
+		}
+		tags := "-"
+		if len(l.Tags) > 0 {
+			tags = strings.Join(l.Tags, ", ")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d min\t%s\t%s\n", i+1, l.ID, status, l.Difficulty, l.Minutes, requires, tags)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nAbout %d min to go.\n", left)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func lessonIDs(ls []Lesson) string {
+	var ids []string
+	for _, l := range ls {
+		ids = append(ids, l.ID)
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestCurriculum(t *testing.T) {
+	// the registry is in the order the tour is meant to be read
+	order, err := curriculum(lessons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lessonIDs(order), lessonIDs(lessons); got != want {
+		t.Errorf("curriculum is\n%s\nnot the registry order\n%s", got, want)
+	}
+	for _, l := range lessons {
+		if l.Minutes <= 0 || len(l.Tags) == 0 {
+			t.Errorf("lesson %s has no estimated time or no tags", l.ID)
+		}
+	}
+
+	ls := []Lesson{
+		{ID: "c", Requires: []string{"b"}},
+		{ID: "a"},
+		{ID: "b", Requires: []string{"a"}, Tags: []string{"x"}},
+		{ID: "d", Requires: []string{"a"}, Tags: []string{"x"}},
+	}
+	order, err = curriculum(ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lessonIDs(order), "a b c d"; got != want {
+		t.Errorf("curriculum is %q, want %q", got, want)
+	}
+	for topic, want := range map[string]string{"c": "a b c", "x": "a b d", "a": "a"} {
+		path, err := pathTo(ls, topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lessonIDs(path); got != want {
+			t.Errorf("path to %s is %q, want %q", topic, got, want)
+		}
+	}
+	if _, err := pathTo(ls, "y"); err == nil {
+		t.Error("path to an unknown topic did not fail")
+	}
+
+	ls[1].Requires = []string{"c"}
+	_, err = curriculum(ls)
+	if want := "cycle: c -> b -> a -> c"; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("got error %v, want one ending in %q", err, want)
+	}
+	ls[1].Requires = []string{"e"}
+	if _, err := curriculum(ls); err == nil {
+		t.Error("an unknown prerequisite was not reported")
+	}
+}
+
+func TestJumpAhead(t *testing.T) {
+	p := &progress{Lessons: map[string]*lessonProgress{
+		"variables": {Completed: time.Now()},
+		"functions": {Step: 2},
+	}}
+	l, _ := findLesson("first-class")
+	var b strings.Builder
+	warnJumpAhead(&b, p, l)
+	if want := "Heads up: first-class builds on functions, arrays, slices, maps, control-flow,"; !strings.HasPrefix(b.String(), want) {
+		t.Errorf("got %q, want it to start with %q", b.String(), want)
+	}
+	l, _ = findLesson("printing")
+	b.Reset()
+	warnJumpAhead(&b, p, l)
+	if b.Len() != 0 {
+		t.Errorf("warned about printing: %q", b.String())
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// tourOptions change how the lessons print what they demonstrate.
+type tourOptions struct {
+	// deterministic makes the output the same on every run, so that it can
+	// be diffed and compared with golden files: addresses are replaced by
+	// symbolic tokens and maps are ranged over in key order.
+	deterministic bool
+	// sortedMaps also shows map iteration in key order after the random one.
+	sortedMaps bool
+	// lang is the language the narrative is shown in, see tr.
+	lang string
+	// packs lists the directories lesson packs are loaded from, see packDirs.
+	packs string
+}
+
+// options are the tourOptions in effect, set from the global command line
+// flags by runCommand.
+var options tourOptions
+
+// deterministicEnv turns on deterministic mode, as the -deterministic flag.
+const deterministicEnv = "GONUTSHELL_DETERMINISTIC"
+
+// envDeterministic reports whether deterministicEnv asks for deterministic
+// output, which any value strconv.ParseBool accepts as true does.
+func envDeterministic() bool {
+	on, _ := strconv.ParseBool(os.Getenv(deterministicEnv))
+	return on
+}
+
+// addrPattern matches the hexadecimal addresses printed by %p; they are
+// longer than the small numbers the lessons print with %#x.
+var addrPattern = regexp.MustCompile(`0x[0-9a-f]{8,}`)
+
+// addrNormalizer replaces every address written through it with a token,
+// 0xADDR1, 0xADDR2, ... in order of appearance. The same address gets the
+// same token each time.
+type addrNormalizer struct {
+	w      io.Writer
+	tokens map[string]string
+}
+
+func (an *addrNormalizer) Write(p []byte) (int, error) {
+	out := addrPattern.ReplaceAllStringFunc(string(p), func(addr string) string {
+		tok, ok := an.tokens[addr]
+		if !ok {
+			tok = fmt.Sprintf("0xADDR%d", len(an.tokens)+1)
+			an.tokens[addr] = tok
+		}
+		return tok
+	})
+	if _, err := io.WriteString(an.w, out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lessonWriter returns the writer a lesson should print to so that its
+// output ends up in w, normalized in deterministic mode.
+func lessonWriter(w io.Writer) io.Writer {
+	if !options.deterministic {
+		return w
+	}
+	return &addrNormalizer{w: w, tokens: map[string]string{}}
+}
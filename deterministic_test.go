@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddrNormalizer(t *testing.T) {
+	var out strings.Builder
+	w := &addrNormalizer{w: &out, tokens: map[string]string{}}
+	for _, s := range []string{
+		"p1 = 0xc000012345\n",
+		"p2 = 0x0, %#x = 0x20\n",
+		"x = 0xc000067890, p1 again = 0xc000012345\n",
+	} {
+		w.Write([]byte(s))
+	}
+	want := "p1 = 0xADDR1\n" +
+		"p2 = 0x0, %#x = 0x20\n" +
+		"x = 0xADDR2, p1 again = 0xADDR1\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "strings"
+
+// diffLine is one line of a line diff: op is ' ' for a line both sides
+// have, '-' for a line only in the first and '+' for one only in the second.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines diffs a against b using their longest common subsequence. The
+// texts compared here are a few lines long, so the quadratic table is fine.
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var d []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			d = append(d, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			d = append(d, diffLine{'-', a[i]})
+			i++
+		default:
+			d = append(d, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return d
+}
+
+// outputLines splits program output into lines for diffing, with the
+// white space of every line collapsed and the trailing empty lines dropped.
+func outputLines(s string) []string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = collapseSpace(l)
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
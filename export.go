@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// docStep is a step of a lesson along with the output of its code.
+type docStep struct {
+	step
+	Output string
+}
+
+// docLesson is a lesson as it is written out by the exporters.
+type docLesson struct {
+	Lesson
+	Steps      []docStep
+	Prev, Next *docLesson
+}
+
+// tourDoc is the whole tour, ready to be exported.
+type tourDoc struct {
+	Title   string
+	Intro   string
+	Lessons []*docLesson
+}
+
+// buildTourDoc splits the lessons in sel into steps. With run it runs them
+// too, for the output of each step.
+func buildTourDoc(sel []Lesson, run bool) (*tourDoc, error) {
+	intro, err := tourIntro()
+	if err != nil {
+		return nil, err
+	}
+	doc := &tourDoc{Title: tr("title", tourTitle), Intro: tr("intro", intro)}
+	for _, l := range sel {
+		steps, err := lessonSteps(l)
+		if err != nil {
+			return nil, err
+		}
+		var out transcript
+		if run {
+			out = captureLesson(l)
+		}
+		dl := &docLesson{Lesson: l}
+		dl.Title = lessonTitle(l)
+		for _, s := range localizeSteps(l, steps) {
+			dl.Steps = append(dl.Steps, docStep{step: s, Output: strings.TrimRight(s.output(out), "\n")})
+		}
+		if n := len(doc.Lessons); n > 0 {
+			dl.Prev, doc.Lessons[n-1].Next = doc.Lessons[n-1], dl
+		}
+		doc.Lessons = append(doc.Lessons, dl)
+	}
+	return doc, nil
+}
+
+// tourTitle is the title of the tour.
+const tourTitle = "Go in a nutshell"
+
+// tourIntro returns the introduction of the tour, the package comment.
+func tourIntro() (string, error) {
+	t, err := loadTour()
+	if err != nil {
+		return "", err
+	}
+	if f := t.files[0]; f.Doc != nil {
+		return commentText(f.Doc.List[0]), nil
+	}
+	return "", nil
+}
+
+// writeMarkdown writes doc as a single Markdown file: lessons and '===='
+// steps become level 2 and 3 headings, '---' steps level 4 ones.
+func writeMarkdown(w io.Writer, doc *tourDoc) error {
+	fmt.Fprintf(w, "# %s\n\n%s\n\n", doc.Title, doc.Intro)
+	for _, l := range doc.Lessons {
+		fmt.Fprintf(w, "- [%s](#%s)\n", l.Title, l.ID)
+	}
+	for _, l := range doc.Lessons {
+		fmt.Fprintf(w, "\n<a id=\"%s\"></a>\n\n## %s\n", l.ID, l.Title)
+		for _, s := range l.Steps {
+			fmt.Fprintf(w, "\n%s %s\n", strings.Repeat("#", s.Level+2), s.Heading)
+			for _, n := range s.Notes {
+				fmt.Fprintf(w, "\n%s\n", n)
+			}
+			if s.Code != "" {
+				fmt.Fprintf(w, "\n```go\n%s\n```\n", s.Code)
+			}
+			if s.Output != "" {
+				fmt.Fprintf(w, "\nOutput:\n\n```text\n%s\n```\n", s.Output)
+			}
+		}
+	}
+	return nil
+}
+
+// writeHTML writes doc as a static site into dir: an index page and a page
+// per lesson, with the style sheet inlined so that nothing else is needed.
+func writeHTML(dir string, doc *tourDoc) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	write := func(name, tmpl string, data any) error {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := htmlTemplates.ExecuteTemplate(f, tmpl, data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	if err := write("index.html", "index", doc); err != nil {
+		return err
+	}
+	for _, l := range doc.Lessons {
+		if err := write(l.ID+".html", "lesson", struct {
+			*tourDoc
+			Lesson *docLesson
+		}{doc, l}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var htmlTemplates = template.Must(template.New("").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; max-width: 52em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f4f4f4; padding: .75em; overflow-x: auto; tab-size: 4; }
+pre.output { background: #222; color: #ddd; }
+.note { white-space: pre-wrap; }
+nav { display: flex; justify-content: space-between; margin: 2em 0; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "index"}}{{template "head" .Title}}<h1>{{.Title}}</h1>
+<p class="note">{{.Intro}}</p>
+<ol>
+{{range .Lessons}}<li><a href="{{.ID}}.html">{{.Title}}</a></li>
+{{end}}</ol>
+</body>
+</html>
+{{end}}
+
+{{define "lesson"}}{{template "head" .Lesson.Title}}<p><a href="index.html">{{.Title}}</a></p>
+<h1>{{.Lesson.Title}}</h1>
+{{range .Lesson.Steps}}{{if eq .Level 1}}<h2>{{.Heading}}</h2>{{else}}<h3>{{.Heading}}</h3>{{end}}
+{{range .Notes}}<p class="note">{{.}}</p>
+{{end}}{{if .Code}}<pre><code>{{.Code}}</code></pre>
+{{end}}{{if .Output}}<pre class="output">{{.Output}}</pre>
+{{end}}{{end}}<nav>
+<span>{{with .Lesson.Prev}}&larr; <a href="{{.ID}}.html">{{.Title}}</a>{{end}}</span>
+<span>{{with .Lesson.Next}}<a href="{{.ID}}.html">{{.Title}}</a> &rarr;{{end}}</span>
+</nav>
+</body>
+</html>
+{{end}}
+`))
+
+func cmdExport(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(w)
+	md := fs.String("md", "", "write the tour as Markdown to `file` (- for standard output)")
+	site := fs.String("html", "", "write the tour as a static HTML site into `dir`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *md == "" && *site == "" {
+		*md = "-"
+	}
+	sel, err := selectLessons(fs.Args())
+	if err != nil {
+		return err
+	}
+	doc, err := buildTourDoc(sel, true)
+	if err != nil {
+		return err
+	}
+	if *site != "" {
+		if err := writeHTML(*site, doc); err != nil {
+			return err
+		}
+	}
+	switch *md {
+	case "":
+		return nil
+	case "-":
+		return writeMarkdown(w, doc)
+	}
+	f, err := os.Create(*md)
+	if err != nil {
+		return err
+	}
+	if err := writeMarkdown(f, doc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
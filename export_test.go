@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	l, _ := findLesson("slices")
+	doc, err := buildTourDoc([]Lesson{l}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var md strings.Builder
+	if err := writeMarkdown(&md, doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Slices\n",
+		"#### copy - copy (destination <- source)\n",
+		"```go\no1 := []int{1, 2, 3, 4, 5}\n",
+		"```text\no1 = [10 20 30 40 5]\n```\n",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown does not contain %q", want)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := writeHTML(dir, doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		t.Error(err)
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "slices.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "copy - copy (destination &lt;- source)"; !strings.Contains(string(page), want) {
+		t.Errorf("slices.html does not contain %q", want)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+)
+
+// brokenSnippet is code that deliberately does not compile. Code is a list
+// of statements that is type-checked as the body of a function of the tour,
+// so it can call helpers such as sumAndProd or fullName.
+type brokenSnippet struct {
+	ID      string
+	Lesson  string // ID of the lesson that talks about it
+	Explain string
+	Code    string
+	Expect  string // part of the diagnostic the explanation relies on
+}
+
+// gallery is the compile-error gallery, in the order of the tour.
+var gallery = []brokenSnippet{
+	{
+		ID:     "redeclare",
+		Lesson: "functions",
+		Explain: `':=' declares new variables, so it fails when every variable on the
+left is already declared in the same scope. 'sum, prod := sumAndProd(23, 23)'
+on its own would be fine.`,
+		Code: `var sum int
+var prod int
+sum, prod := sumAndProd(23, 23)
+_, _ = sum, prod`,
+		Expect: "no new variables on left side of :=",
+	},
+	{
+		ID:     "else-newline",
+		Lesson: "control-flow",
+		Explain: `'else' has to be on the same line as the closing '}' of the 'if'.
+Otherwise a ';' is inserted after the '}' and the 'else' starts a statement
+of its own, which is a syntax error.`,
+		Code: `if 2 == 3 {
+}
+else {
+}`,
+		Expect: "else",
+	},
+	{
+		ID:     "unused",
+		Lesson: "placeholder",
+		Explain: `A local variable that is declared but never used is an error. The
+place-holder '_ = i1' is the usual way around it while a program is being
+written.`,
+		Code:   `i1 := 2`,
+		Expect: "declared and not used: i1",
+	},
+	{
+		ID:     "slice-as-variadic",
+		Lesson: "variadic",
+		Explain: `A variadic parameter '...string' is not a '[]string' parameter. A slice
+can only be passed to it when it is followed by '...', as in 'fullName(nm...)'.`,
+		Code:   `fullName([]string{"The", "ghost", "who", "walks"})`,
+		Expect: "as string value in argument to fullName",
+	},
+	{
+		ID:     "string-define",
+		Lesson: "strings",
+		Explain: `Written as 'str2[0] := "A"', the line is rejected before strings even
+come into it: only plain names can be declared with ':='.`,
+		Code: `str2 := "abcd"
+str2[0] := "A"`,
+		Expect: "str2[0]",
+	},
+	{
+		ID:     "string-immutable",
+		Lesson: "strings",
+		Explain: `Strings are immutable, so even a plain assignment to one of its bytes
+does not compile. Convert to []rune (or []byte), modify that and convert back.`,
+		Code: `str2 := "abcd"
+str2[0] = 'A'`,
+		Expect: "cannot assign to str2[0]",
+	},
+	{
+		ID:     "struct-compare",
+		Lesson: "structs",
+		Explain: `Structs can be compared with == only when all of their fields can.
+Slices cannot, so neither can a struct with a slice field. Compare the fields
+one by one instead, the slice with slices.Equal.`,
+		Code: `type team struct {
+	Name    string
+	Members []string
+}
+t1, t2 := team{}, team{}
+_ = t1 == t2`,
+		Expect: "struct containing []string cannot be compared",
+	},
+	{
+		ID:     "unaddressable",
+		Lesson: "structs",
+		Explain: `Move has a pointer receiver, so calling it needs the address of the
+point. A map element has none, as the map moves its elements around when it
+grows. Copy the element to a variable, move that and store it back.`,
+		Code: `m := map[string]point{"a": {1, 2}}
+m["a"].Move(1, 1)`,
+		Expect: "cannot call pointer method Move on point",
+	},
+	{
+		ID:     "not-a-mover",
+		Lesson: "interfaces",
+		Explain: `Move has a pointer receiver, so it is in the method set of *point but
+not of point, and only a *point satisfies mover. Assign &point{1, 2} instead.`,
+		Code: `type mover interface {
+	Move(dx, dy int)
+}
+var m mover = point{1, 2}
+_ = m`,
+		Expect: "method Move has pointer receiver",
+	},
+}
+
+// diagnostic is a compiler message about a line of a snippet.
+type diagnostic struct {
+	line, col int
+	msg       string
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.line, d.col, d.msg)
+}
+
+// galleryWrapLines is the number of lines compileSnippet puts before the code.
+const galleryWrapLines = 3
+
+// compileSnippet parses and type-checks s along with the tour and returns
+// the diagnostics reported for the snippet, with lines counted from its
+// first line.
+func compileSnippet(s brokenSnippet) ([]diagnostic, error) {
+	t, err := loadTour()
+	if err != nil {
+		return nil, err
+	}
+	name := "gallery_" + strings.ReplaceAll(s.ID, "-", "_") + ".go"
+	src := fmt.Sprintf("package main\n\nfunc %s() {\n%s\n}\n", strings.TrimSuffix(name, ".go"), s.Code)
+	lines := strings.Count(s.Code, "\n") + 1
+	var diags []diagnostic
+	add := func(pos token.Position, msg string) {
+		// the parser may also trip over the end of the wrapping func
+		if line := pos.Line - galleryWrapLines; pos.Filename == name && line >= 1 && line <= lines {
+			diags = append(diags, diagnostic{line: line, col: pos.Column, msg: msg})
+		}
+	}
+	f, err := parser.ParseFile(t.fset, name, src, 0)
+	if err != nil {
+		// syntax errors stop the compiler before type-checking
+		if list, ok := err.(scanner.ErrorList); ok {
+			for _, e := range list {
+				add(e.Pos, e.Msg)
+			}
+			return diags, nil
+		}
+		return nil, err
+	}
+	_, _, errs := typeCheck(t.fset, append(append([]*ast.File{}, t.files...), f))
+	for _, e := range errs {
+		if te, ok := e.(types.Error); ok {
+			add(te.Fset.Position(te.Pos), te.Msg)
+		}
+	}
+	return diags, nil
+}
+
+func cmdGallery(w io.Writer, args []string) error {
+	sel := gallery
+	if len(args) > 0 {
+		sel = nil
+		for _, a := range args {
+			n := len(sel)
+			for _, s := range gallery {
+				if s.ID == a || s.Lesson == a {
+					sel = append(sel, s)
+				}
+			}
+			if len(sel) == n {
+				return fmt.Errorf("no snippet or lesson %q in the gallery", a)
+			}
+		}
+	}
+	for i, s := range sel {
+		diags, err := compileSnippet(s)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "--- %s (lesson %s)\n%s\n\n", s.ID, s.Lesson, s.Explain)
+		for _, l := range strings.Split(s.Code, "\n") {
+			fmt.Fprintf(w, "\t%s\n", l)
+		}
+		fmt.Fprintln(w)
+		if len(diags) == 0 {
+			fmt.Fprintln(w, "compiles without errors!")
+		}
+		for _, d := range diags {
+			fmt.Fprintf(w, "compiler: %s\n", d)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGalleryDoesNotCompile(t *testing.T) {
+	for _, s := range gallery {
+		diags, err := compileSnippet(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s.ID, err)
+		}
+		if len(diags) == 0 {
+			t.Errorf("%s: compiles, but the gallery says it does not", s.ID)
+			continue
+		}
+		found := false
+		for _, d := range diags {
+			found = found || strings.Contains(d.msg, s.Expect)
+		}
+		if !found {
+			t.Errorf("%s: no diagnostic mentions %q, got %v", s.ID, s.Expect, diags)
+		}
+		if _, ok := findLesson(s.Lesson); !ok {
+			t.Errorf("%s: unknown lesson %q", s.ID, s.Lesson)
+		}
+	}
+}
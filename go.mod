@@ -0,0 +1,3 @@
+module gonutshell
+
+go 1.25
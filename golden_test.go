@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestLessonsGolden runs every lesson in deterministic mode and compares its
+// output with testdata/<lesson>.golden. Run 'go test -update' to rewrite
+// the golden files after a lesson has deliberately changed.
+func TestLessonsGolden(t *testing.T) {
+	defer func(o tourOptions) { options = o }(options)
+	options = tourOptions{deterministic: true}
+	for _, l := range lessons {
+		t.Run(l.ID, func(t *testing.T) {
+			var out bytes.Buffer
+			l.Run(lessonWriter(&out))
+			golden := filepath.Join("testdata", l.ID+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run 'go test -update' to create it)", err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Errorf("output differs from %s:\n%s", golden, lineDiff(string(want), got))
+			}
+		})
+	}
+}
+
+// lineDiff describes the first line where got differs from want.
+func lineDiff(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		w, g := "<none>", "<none>"
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n\twant: %q\n\tgot:  %q", i+1, w, g)
+		}
+	}
+	return "(no difference)"
+}
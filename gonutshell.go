@@ -1,6 +1,10 @@
 /*
 This gonutshell package will attempt to to present a quick tour of
 golang programming concepts with some toy examples.
+
+Comments of the form '// Output: ...' state what the statements just
+before them (or on the same line) print. 'gonutshell verify' runs the
+lessons and checks every one of them against the real output.
 */
 // declare package as type main to make it executable
 package main
@@ -8,13 +12,34 @@ package main
 // import required packages
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	_ "fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 )
 
-// define entry point
+/*
+define entry point
+Each topic of the tour is a 'lesson' function below, registered
+in lesson.go. With no arguments all of them are run in order,
+see 'gonutshell help' for running them one at a time.
+*/
 func main() {
+	os.Exit(runCommand(os.Stdout, os.Stderr, os.Args[1:]))
+}
+
+func lessonVariables(w io.Writer) {
 	// ==== Variable declaration =====
 	var x int
 	/*
@@ -34,95 +59,113 @@ func main() {
 	const knst2 int = 56
 	// type not specified
 	const knst3 = "hello there"
-	fmt.Printf("Types of knst1=%T & knst3=%T\n", knst1, knst3)
+	fmt.Fprintf(w, "Types of knst1=%T & knst3=%T\n", knst1, knst3)
+	// printing values using 'fmt.Fprintf'
+	fmt.Fprintf(w, "x = %d\n", x)
+	fmt.Fprintf(w, "y = %f\n", y)
+}
+
+func lessonPrinting(w io.Writer) {
 	// ==== Formatted Printing to Console ====
-	// printing values using 'fmt.Printf' method
-	fmt.Printf("x = %d\n", x)
-	fmt.Printf("y = %f\n", y)
+	x, y := 23, 3.14159 // as in the Variables lesson
 	/*
 		Printf - works like 'printf' in C
 		It takes a string with 'format specifiers'
 		(called verbs) and then values that are
 		formatted and printed replacing the verbs
 		(using the indicated format)
+
+		NOTE: the lessons use 'fmt.Fprintf', which is the same
+		as Printf but writes to the given io.Writer 'w' instead
+		of always writing to the console.
 	*/
 	// common format specifier verbs
 	// integer
-	fmt.Printf("%d\n", 43)
+	fmt.Fprintf(w, "%d\n", 43)
 	// integer with padding
-	fmt.Printf("%04d\n", 43)
+	fmt.Fprintf(w, "%04d\n", 43)
 	// integer padding with space
-	fmt.Printf("% 4d\n", 43)
+	fmt.Fprintf(w, "% 4d\n", 43)
 	// char, quoted char
-	fmt.Printf("%c - %q\n", 'Z', 'Z')
+	fmt.Fprintf(w, "%c - %q\n", 'Z', 'Z')
 	// binary , octal, hex
-	fmt.Printf("%b - %o - %x - %#x\n", 32, 32, 32, 32)
+	fmt.Fprintf(w, "%b - %o - %x - %#x\n", 32, 32, 32, 32)
 	// float
-	fmt.Printf("%f\n", 31.3)
+	fmt.Fprintf(w, "%f\n", 31.3)
 	// float with precission
-	fmt.Printf("%06.2f\n", 31.3)
+	fmt.Fprintf(w, "%06.2f\n", 31.3)
 	// float exponential notation
-	fmt.Printf("%e\n", 31.3)
+	fmt.Fprintf(w, "%e\n", 31.3)
 	// bool
-	fmt.Printf("%t\n", false)
+	fmt.Fprintf(w, "%t\n", false)
 	// pointer
-	fmt.Printf("%p\n", &x) // address of 'x' in this case
+	fmt.Fprintf(w, "%p\n", &x) // address of 'x' in this case
 	// string, quoted string
-	fmt.Printf("%s - %q\n", "Hello", "Hello")
+	fmt.Fprintf(w, "%s - %q\n", "Hello", "Hello")
 	// *** default format
-	fmt.Printf("%v\n", []int{1, 2}) // value is a 'slice'
-	// [1 2]
+	fmt.Fprintf(w, "%v\n", []int{1, 2}) // value is a 'slice'
+	// Output: [1 2]
 	// *** Go code format
-	fmt.Printf("%#v\n", []int{1, 2}) // value is a 'slice'
-	// []int{1, 2}
+	fmt.Fprintf(w, "%#v\n", []int{1, 2}) // value is a 'slice'
+	// Output: []int{1, 2}
 	// *** print the 'type'
-	fmt.Printf("%T\n", []int{1, 2}) // value is a 'slice'
-	// []int
+	fmt.Fprintf(w, "%T\n", []int{1, 2}) // value is a 'slice'
+	// Output: []int
 	// we can use this to print the type of variables
-	fmt.Printf("Type of 'y' = %T\n", y)
+	fmt.Fprintf(w, "Type of 'y' = %T\n", y)
 
 	// ==== Formatted Print output to string ====
 	fs := fmt.Sprintf("%x", 2047)
-	fmt.Println(fs)
+	fmt.Fprintln(w, fs)
 	// ==== Printing without format specification ====
-	fmt.Print("Print Hello.. ")
-	fmt.Println("Println Hello!")
+	fmt.Fprint(w, "Print Hello.. ")
+	fmt.Fprintln(w, "Println Hello!")
+}
+
+func lessonFunctions(w io.Writer) {
 	// ==== Invoking functions ====
-	printDouble(23)
-	fmt.Printf("Triple of %d = %d\n", 23, triple(23))
+	printDouble(w, 23)
+	fmt.Fprintf(w, "Triple of %d = %d\n", 23, triple(23))
 	// *** variable destructuring - multiple assignment
 	var sum int
 	var prod int
 	sum, prod = sumAndProd(23, 23)
-	// NOTE: sum, prod := sumAndProd - does NOT work!
-	fmt.Printf("Sum = %d; Prod = %d\n", sum, prod)
+	// NOTE: sum, prod := sumAndProd(23, 23) does NOT work here, as both
+	// are declared already - see 'gonutshell gallery redeclare'
+	fmt.Fprintf(w, "Sum = %d; Prod = %d\n", sum, prod)
 	q, p := quadAndPentaple(23)
-	fmt.Printf("Quad = %d; Pent = %d\n", q, p)
+	fmt.Fprintf(w, "Quad = %d; Pent = %d\n", q, p)
 	/*
 		NOTE: now direct assignment q, p := quadAndPentaple() works!
 		This is because the return values in 'quadAndPentaple' are named.
 	*/
-	fmt.Printf("Hex = %d\n", hexaple(23))
+	fmt.Fprintf(w, "Hex = %d\n", hexaple(23))
+}
+
+func lessonConversions(w io.Writer) {
 	// ==== Type conversions ====
 	b := byte('\n')
-	fmt.Printf("Value of 'b' = %v; Type of 'b' = %T\n", b, b)
-	// Value of 'b' = 10; Type of 'b' = uint8
+	fmt.Fprintf(w, "Value of 'b' = %v; Type of 'b' = %T\n", b, b)
+	// Output: Value of 'b' = 10; Type of 'b' = uint8
 	a := float32(3)
-	fmt.Printf("Value of 'a' = %v\n", a)
+	fmt.Fprintf(w, "Value of 'a' = %v\n", a)
 	c := 'a'
-	fmt.Printf("Type of 'c' = %T\n", c)
-	// Type of 'c' = uint32
+	fmt.Fprintf(w, "Type of 'c' = %T\n", c)
+	// Output: Type of 'c' = int32
+	// NOTE: 'c' is a rune, which is an alias for int32
+}
 
+func lessonArrays(w io.Writer) {
 	// ==== Standard built-in collections ====
 	// *** Arrays - fixed size collection
 	var a4 [4]int
-	fmt.Printf("a4 value = %v; a4 type = %T\n", a4, a4)
-	// a4 value = [0 0 0 0]; a4 type = [4]int
+	fmt.Fprintf(w, "a4 value = %v; a4 type = %T\n", a4, a4)
+	// Output: a4 value = [0 0 0 0]; a4 type = [4]int
 	// NOTE: int32 array of 4 values, init to 0
 
 	a5 := [...]int{10, 20, 30, 40, 50}
-	fmt.Printf("a5 value = %v; a5 type = %T\n", a5, a5)
-	// a5 value = [10 20 30 40 50]; a5 type = [4]int
+	fmt.Fprintf(w, "a5 value = %v; a5 type = %T\n", a5, a5)
+	// Output: a5 value = [10 20 30 40 50]; a5 type = [5]int
 	/*
 		NOTE: int32 array of 5 values, init to [10 20 30 40 50]
 		NOTE: the ellipses '...' is required! It differentiates
@@ -131,54 +174,57 @@ func main() {
 	// Arrays are value types
 	b5 := a5
 	a5[0] = 100
-	fmt.Printf("b5 = %v\n", b5)
+	fmt.Fprintf(w, "b5 = %v\n", b5)
 	// b5 is not affected, it is a copy!
+}
 
+func lessonSlices(w io.Writer) {
 	// *** Slices - variable size collection
 	// Similar to Lists in Python
 	var s1 []int // declaration only, nothing allocated
 	// --- append to slice - built-in function 'append'
 	s1 = append(s1, 1, 3, 5, 7)
-	fmt.Printf("s1 value = %v; s1 type = %T\n", s1, s1)
+	fmt.Fprintf(w, "s1 value = %v; s1 type = %T\n", s1, s1)
 	// indexing range of values
-	fmt.Printf("s1[0:1] = %v\n", s1[0:1])             // [1]
-	fmt.Printf("s1[0:2] = %v\n", s1[0:2])             // [1 3]
-	fmt.Printf("s1[0:len(s1)] = %v\n", s1[0:len(s1)]) // [1 3 5 7]
+	fmt.Fprintf(w, "s1[0:1] = %v\n", s1[0:1])             // Output: s1[0:1] = [1]
+	fmt.Fprintf(w, "s1[0:2] = %v\n", s1[0:2])             // Output: s1[0:2] = [1 3]
+	fmt.Fprintf(w, "s1[0:len(s1)] = %v\n", s1[0:len(s1)]) // Output: s1[0:len(s1)] = [1 3 5 7]
 	// full length of the slice using 'len()'
-	fmt.Printf("s1[1:] = %v\n", s1[1:]) // [3 5 7]
+	fmt.Fprintf(w, "s1[1:] = %v\n", s1[1:]) // Output: s1[1:] = [3 5 7]
 	// [<low>:] = <low> - till -> <end>
-	fmt.Printf("s1[:3] = %v\n", s1[:3]) // [1 3 5]
+	fmt.Fprintf(w, "s1[:3] = %v\n", s1[:3]) // Output: s1[:3] = [1 3 5]
 	// [:<high>] = <0> - till -> <high>
 
 	// --- allocate a slice uisng - make()
 	a1 := make([]string, 5)
 	// allocate a slice of strings with size 5, inited to ""
-	fmt.Printf("a1 = %v\n", a1)
+	fmt.Fprintf(w, "a1 = %v\n", a1)
 
 	// --- copy - copy (destination <- source)
 	o1 := []int{1, 2, 3, 4, 5}
 	e1 := []int{10, 20, 30, 40}
 	copy(o1, e1)
-	fmt.Printf("o1 = %v\n", o1) // [10 20 30 40 5]
+	fmt.Fprintf(w, "o1 = %v\n", o1) // Output: o1 = [10 20 30 40 5]
 	// NOTE: Any overflow from source will be ignored
 	// --- copy - with sub-range
 	o2 := []int{1, 2, 3, 4, 5}
 	copy(o2[1:4], e1)
-	fmt.Printf("o2 = %v\n", o2) // [1 10 20 30 5]
+	fmt.Fprintf(w, "o2 = %v\n", o2) // Output: o2 = [1 10 20 30 5]
 	// NOTE: elements at indices 1, 2, 3 are replaced by e1
 	copy(o2[1:4], e1[1:])
-	fmt.Printf("o2 = %v\n", o2) // [1 20 30 40 5]
+	fmt.Fprintf(w, "o2 = %v\n", o2) // Output: o2 = [1 20 30 40 5]
 	copy(o2[1:4], e1[2:])
-	fmt.Printf("o2 = %v\n", o2) // [1 30 40 30 5]
-	// NOTE: source slice [30, 40] copied cyclically!
+	fmt.Fprintf(w, "o2 = %v\n", o2) // Output: o2 = [1 30 40 40 5]
+	// NOTE: copy stops at the shorter slice, only [30 40] is copied
+	// and o2[3] keeps its old value
 
 	// --- delete from slice - fast - order not preserved
 	i := 1
-	s1[i] = s1[len(s1)-1]                             // copy last element to position 'i'
-	s1[len(s1)-1] = 0                                 // "zero" last element
-	s1 = s1[:len(s1)-1]                               // truncate the slice without last element
-	fmt.Printf("s1 with 2nd item deleted = %v\n", s1) // [1 7 5]
-	// NOTE: This has constant time complexity
+	s1[i] = s1[len(s1)-1]                                 // copy last element to position 'i'
+	s1[len(s1)-1] = 0                                     // "zero" last element
+	s1 = s1[:len(s1)-1]                                   // truncate the slice without last element
+	fmt.Fprintf(w, "s1 with 2nd item deleted = %v\n", s1) // Output: s1 with 2nd item deleted = [1 7 5]
+	// NOTE: This has constant time complexity, see 'gonutshell run complexity'
 
 	// --- delete from slice - slow - order preserved
 	s2 := []int{1, 2, 3, 4, 5}
@@ -188,9 +234,11 @@ func main() {
 	// reset last value as it is redundant now
 	s2 = s2[:len(s2)-1]
 	// truncate slice without last element
-	fmt.Printf("s2 with 2nd item deleted = %v\n", s2) // [1 3 4 5]
-	// NOTE: This has linear time complexity
+	fmt.Fprintf(w, "s2 with 2nd item deleted = %v\n", s2) // Output: s2 with 2nd item deleted = [1 3 4 5]
+	// NOTE: This has linear time complexity, see 'gonutshell run complexity'
+}
 
+func lessonMaps(w io.Writer) {
 	// *** Maps - variable size associative arrays
 	/*
 		Similar to Dictionaries/Hash Tables
@@ -215,11 +263,11 @@ func main() {
 	var sr int
 	var found bool
 	sr, found = scores["Bob"]
-	fmt.Printf("Bob's score = %d; found = %v\n", sr, found)
-	// Bob's score = 72; found = true
+	fmt.Fprintf(w, "Bob's score = %d; found = %v\n", sr, found)
+	// Output: Bob's score = 72; found = true
 	sr, found = scores["Ron"]
-	fmt.Printf("Ron's score = %d; found = %v\n", sr, found)
-	// Ron's score = 0; found = false
+	fmt.Fprintf(w, "Ron's score = %d; found = %v\n", sr, found)
+	// Output: Ron's score = 0; found = false
 	/*
 		NOTE: In idiomatic Go style, accessing a map element is
 		returns two values. The second is a boolean value that
@@ -229,34 +277,37 @@ func main() {
 		To ignore a returned value use '_'
 	*/
 	// --- number of items - len() ---
-	fmt.Printf("Num of days = %d\n", len(days))
+	fmt.Fprintf(w, "Num of days = %d\n", len(days))
 
 	// --- delete from a map - delete() ---
 	delete(scores, "Bob")
-	fmt.Println(scores)
-	// map[Alan: 83 Cathy: 91]
+	fmt.Fprintln(w, scores)
+	// Output: map[Alan:83 Cathy:91]
 	// NOTE: If the key is not found, 'delete' does nothing
+}
 
+func lessonControlFlow(w io.Writer) {
 	// ==== Control-flow commands ====
 	// *** conditionals
 	// --- if / else ---
 	if 2 == 3 {
 		// NOTE: the 'condition' does not need ()
 		// The body requires {}
-		fmt.Println("Inside '2 == 3'")
+		fmt.Fprintln(w, "Inside '2 == 3'")
 	} else if 2 == 2.0 {
-		fmt.Println("Inside 2 == 2.0")
+		fmt.Fprintln(w, "Inside 2 == 2.0")
 	} else {
 		// NOTE: 'else' has to be inline with the } .. {
-		fmt.Println("Inside 'else'")
+		// see 'gonutshell gallery else-newline'
+		fmt.Fprintln(w, "Inside 'else'")
 	}
 	// --- if with initialization! ---
 	if i1, i2 := 2.0*22/7, 3.414*2; i1 > i2 {
-		fmt.Printf("%v > %v\n", i1, i2)
+		fmt.Fprintf(w, "%v > %v\n", i1, i2)
 	} else {
-		fmt.Printf("%v > %v\n", i2, i1)
+		fmt.Fprintf(w, "%v > %v\n", i2, i1)
 	}
-	//6.828 > 6.285714285714286
+	// Output: 6.828 > 6.285714285714286
 	// --- switch / case ---
 	sw1 := 20
 	var r1 string
@@ -272,7 +323,7 @@ func main() {
 	default:
 		r1 = "Undefined"
 	}
-	fmt.Println(r1)
+	fmt.Fprintln(w, r1)
 	// NOTE: Switch in Go has no break!
 	// switch with expression cases
 	switch {
@@ -283,7 +334,7 @@ func main() {
 	default:
 		r1 = "Above 20"
 	}
-	fmt.Println(r1)
+	fmt.Fprintln(w, r1)
 	switch {
 	case sw1 >= 10:
 		r1 = "At 10"
@@ -294,8 +345,8 @@ func main() {
 	default:
 		r1 = "Out of range"
 	}
-	fmt.Println(r1)
-	// At 10
+	fmt.Fprintln(w, r1)
+	// Output: At 10
 	/*
 		NOTE: Switch in Go has no fall-through, which is why in the
 		above example even though sw1=20, the first case condition
@@ -309,12 +360,12 @@ func main() {
 			<body>
 		}
 	*/
-	fmt.Println()
+	fmt.Fprintln(w)
 	for sw1 := 1; sw1 < 10; sw1++ {
-		fmt.Printf("%d ", sw1)
+		fmt.Fprintf(w, "%d ", sw1)
 	}
-	fmt.Println()
-	//1 2 3 4 5 6 7 8 9
+	fmt.Fprintln(w)
+	// Output: 1 2 3 4 5 6 7 8 9
 	/*
 		NOTE:  The variable 'sw1' in the for loop is different from the one
 		outside. It is in the scope of the for loop only, which is why
@@ -323,15 +374,15 @@ func main() {
 	// --- initialization & post can be separate
 	sw1 = 1
 	for sw1 < 10 {
-		fmt.Printf("%d ", sw1)
+		fmt.Fprintf(w, "%d ", sw1)
 		sw1++
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 	// --- 'break' and 'continue'
 	sw1 = 0
 	for {
 		sw1++ // inc loop variable
-		fmt.Printf("%d ", sw1)
+		fmt.Fprintf(w, "%d ", sw1)
 		if sw1 >= 10 {
 			break // exit loop
 		}
@@ -340,13 +391,13 @@ func main() {
 		}
 		sw1++ // inc loop variable again!
 	}
-	fmt.Println()
-	// 1 3 5 7 8 9 10
+	fmt.Fprintln(w)
+	// Output: 1 3 5 7 8 9 10
 	// --- multiple variables
 	for i, j := 1, 10; i <= 10 || j <= 30; i, j = i+1, j+10 {
-		fmt.Printf("(%d, %d)", i, j)
+		fmt.Fprintf(w, "(%d, %d)", i, j)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 	/*
 		NOTE:The loop will execute till the 'condition' becomes false
 		 => in this case loop will terminate only when (i > 10) AND (j > 30)
@@ -359,35 +410,61 @@ func main() {
 	*/
 	// --- 'range' to iterate over collections
 	for i, v := range [...]rune{'A', 'B', 'C', 'D', 'E'} {
-		fmt.Printf("%d:%c ", i, v)
+		fmt.Fprintf(w, "%d:%c ", i, v)
 	}
-	fmt.Println()
-	// 0:A 1:B 2:C 3:D 4:E
+	fmt.Fprintln(w)
+	// Output: 0:A 1:B 2:C 3:D 4:E
 	// NOTE: 'range' returns an index and a value!
 	// --- 'range' over map
-	for k, v := range map[int]rune{1: 'A', 2: 'E', 3: 'I', 4: 'O', 5: 'U'} {
-		fmt.Printf("(%d = %c) ", k, v)
+	vowels := map[int]rune{1: 'A', 2: 'E', 3: 'I', 4: 'O', 5: 'U'}
+	if !options.deterministic {
+		if options.sortedMaps {
+			fmt.Fprint(w, "random order: ")
+		}
+		for k, v := range vowels {
+			fmt.Fprintf(w, "(%d = %c) ", k, v)
+		}
+		fmt.Fprintln(w)
 	}
-	fmt.Println()
-	// (4 = O) (5 = U) (1 = A) (2 = E) (3 = I)
+	// e.g. (4 = O) (5 = U) (1 = A) (2 = E) (3 = I)
 	// NOTE: Order is not preserved for maps
+	// --- 'range' over map in key order
+	if options.deterministic || options.sortedMaps {
+		fmt.Fprint(w, "sorted order: ")
+		for _, k := range slices.Sorted(maps.Keys(vowels)) {
+			fmt.Fprintf(w, "(%d = %c) ", k, vowels[k])
+		}
+		fmt.Fprintln(w)
+	}
+	/*
+		To get a stable order, range over the sorted keys instead.
+		maps.Keys returns an iterator over the keys, which
+		slices.Sorted collects into a sorted slice.
+		NOTE: In deterministic mode only the sorted order is printed,
+		and '-sorted-maps' prints it after the random order.
+	*/
+}
 
+func lessonPlaceholder(w io.Writer) {
 	// === Place-holder identifier ====
 	s3, _ := sumAndProd(23, 23)
-	fmt.Printf("s3 (sum only) = %d\n", s3)
+	fmt.Fprintf(w, "s3 (sum only) = %d\n", s3)
 	// NOTE: can be used to ignore some return values
 	// sometimes used to bypass unused variable check !
+	// see 'gonutshell gallery unused'
 	i1 := 2
 	_ = i1
+}
 
+func lessonVariadic(w io.Writer) {
 	// === Variadic functions - Invocation ===
-	fmt.Println(fullName("John", "Doe"))
-	// John Doe
-	fmt.Println(fullName("Jon", "Von", "Neumann"))
-	// Jon Von Neumann
+	fmt.Fprintln(w, fullName("John", "Doe"))
+	// Output: John Doe
+	fmt.Fprintln(w, fullName("Jon", "Von", "Neumann"))
+	// Output: Jon Von Neumann
 	// NOTE: variable number of names passed in
-	showVar(1, 20, 45, 34, 78)
-	// Type of varidic argument 'prm' = []int
+	showVar(w, 1, 20, 45, 34, 78)
+	// Output: Type of varidic argument 'prm' = []int
 	/*
 		It is converted to a 'Slice of type int' inside
 		the function, however we cannot directly pass
@@ -402,6 +479,7 @@ func main() {
 	*/
 	// fullName([]string{"The", "ghost", "who", "walks"})
 	// the above will result in a compile time type error
+	// see 'gonutshell gallery slice-as-variadic'
 	/*
 		We have to be cognizant that 'variadic function parameter'
 		and 'Slice parameter' are two different type signatures
@@ -411,15 +489,15 @@ func main() {
 		new slice being created!
 	*/
 	nm := []string{"The", "ghost", "who", "walks"}
-	fmt.Println(fullName(nm...))
+	fmt.Fprintln(w, fullName(nm...))
 	// --- Gotcha - Note that the 'slice' can get modified ---
-	fmt.Println(nm) // [The ghost who walks]
+	fmt.Fprintln(w, nm) // Output: [The ghost who walks]
 	change(nm...)
-	fmt.Println(nm) // [Modified! ghost who walks]
+	fmt.Fprintln(w, nm) // Output: [Modified! ghost who walks]
 	// --- Variable type of argument ----
 	/*
-		This can be achived using (empty) 'interface'.
-		More on this later after introducing interfaces!
+		This can be achived using the empty interface 'interface{}',
+		also called 'any', see 'gonutshell run interfaces'.
 
 		for example the 'Printf' function in Go lib 'fmt' is
 		declared as follows -
@@ -428,6 +506,9 @@ func main() {
 			...
 		}
 	*/
+}
+
+func lessonFirstClass(w io.Writer) {
 	// === First-class functions ===
 	/*
 		Functions in Go are first-class citizens just like
@@ -444,14 +525,14 @@ func main() {
 	circClosed := false
 	switchAction := func() {
 		if circClosed {
-			fmt.Println("The ligt is ON!")
+			fmt.Fprintln(w, "The light is ON!")
 		} else {
-			fmt.Println("Light is OFF.")
+			fmt.Fprintln(w, "Light is OFF.")
 		}
 	}
-	switchAction() // Light is OFF.
+	switchAction() // Output: Light is OFF.
 	circClosed = true
-	switchAction() // The light is ON!
+	switchAction() // Output: The light is ON!
 	/*
 		NOTE: The function literal is a 'Closure'. The referenced outer variable
 		'circClosed' is wrapped up in the closure.
@@ -459,10 +540,10 @@ func main() {
 		NOTE: Also how we used an 'anonymous' function!
 	*/
 	// --- HOF - Passing functions as arguments
-	fmt.Println(calc(2, 3, func(x, y int) int { return x + y }))
-	// 2 + 3 = 5
-	fmt.Println(calc(2, 3, func(x, y int) int { return x * y }))
-	// 2 * 3 = 6
+	fmt.Fprintln(w, calc(2, 3, func(x, y int) int { return x + y }))
+	// Output: 5
+	fmt.Fprintln(w, calc(2, 3, func(x, y int) int { return x * y }))
+	// Output: 6
 	// NOTE: The behaviour is injected
 
 	// --- HOF - Returning functions from HOF / function factory
@@ -472,10 +553,10 @@ func main() {
 		NOTE: c1 & c2 are closures over the counter
 		variable 'i'
 	*/
-	fmt.Printf("counter 1 - value = %d\n", c1())
-	// counter 1 - value = 1
-	fmt.Printf("counter 2 - value = %d\n", c2())
-	// counter 2 - value = 101
+	fmt.Fprintf(w, "counter 1 - value = %d\n", c1())
+	// Output: counter 1 - value = 1
+	fmt.Fprintf(w, "counter 2 - value = %d\n", c2())
+	// Output: counter 2 - value = 101
 	/*
 		NOTE: Each instance of the closure has it's own copy
 		of the closed variable, 'init' in this case.
@@ -488,9 +569,11 @@ func main() {
 	sm := ireduce(db, func(x, y int) int {
 		return x + y
 	})
-	fmt.Printf("Sum of doubles = %d\n", sm)
-	// Sum of doubles = 110
+	fmt.Fprintf(w, "Sum of doubles = %d\n", sm)
+	// Output: Sum of doubles = 110
+}
 
+func lessonStrings(w io.Writer) {
 	// ==== Advanced String ====
 	/*
 		A brief forray into how strings are represented in Go,
@@ -501,14 +584,14 @@ func main() {
 		A Go string is a slice of bytes, represented by enclosing in "".
 	*/
 	str1 := "Senior"
-	fmt.Printf("Printing out '%s' as bytes\n", str1)
+	fmt.Fprintf(w, "Printing out '%s' as bytes\n", str1)
 	for i := 0; i < len(str1); i++ {
-		fmt.Printf("%x = %c ", str1[i], str1[i])
+		fmt.Fprintf(w, "%x = %c ", str1[i], str1[i])
 		if i != len(str1)-1 {
-			fmt.Printf("; ")
+			fmt.Fprintf(w, "; ")
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 	// --- Unicode & UTF-8 ---
 	/*
 		Each character in a Go string is stored as a unicode value
@@ -516,14 +599,14 @@ func main() {
 		Let us try some non-english characters -
 	*/
 	str1 = "Señor"
-	fmt.Printf("Printing out '%s' as bytes\n", str1)
+	fmt.Fprintf(w, "Printing out '%s' as bytes\n", str1)
 	for i := 0; i < len(str1); i++ {
-		fmt.Printf("%x = %c ", str1[i], str1[i])
+		fmt.Fprintf(w, "%x = %c ", str1[i], str1[i])
 		if i != len(str1)-1 {
-			fmt.Printf("; ")
+			fmt.Fprintf(w, "; ")
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 	/*
 		OOPS!
 			NOTE: How the character printing results in -
@@ -565,18 +648,18 @@ func main() {
 		for any character!
 	*/
 	// let us cast the string as a slice of runes
-	fmt.Printf("Printing out '%s' as runes\n", str1)
+	fmt.Fprintf(w, "Printing out '%s' as runes\n", str1)
 	rns1 := []rune(str1) // as slice of runes
 	for i := 0; i < len(rns1); i++ {
-		fmt.Printf("%x = %c", rns1[i], rns1[i])
+		fmt.Fprintf(w, "%x = %c", rns1[i], rns1[i])
 		if i != len(rns1)-1 {
-			fmt.Printf("; ")
+			fmt.Fprintf(w, "; ")
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
 	// --- for-range loop on strings ---
 	for i, r := range str1 {
-		fmt.Printf("rune at %d = %c\n", i, r)
+		fmt.Fprintf(w, "rune at %d = %c\n", i, r)
 	}
 	/*
 		rune at 0 = S
@@ -592,16 +675,16 @@ func main() {
 	// --- combining bytes to get string ---
 	byts1 := []byte{0x53, 0x65, 0xc3, 0xb1, 0x6f, 0x72}
 	str1 = string(byts1)
-	fmt.Printf("%x bytes as string = %s\n", byts1, str1)
+	fmt.Fprintf(w, "%x bytes as string = %s\n", byts1, str1)
 	// NOTE: 6 bytes become 5 character string
 	// --- combining runes to get string ---
 	rns1 = []rune{0x53, 0x65, 0xf1, 0x6f, 0x72}
 	str1 = string(rns1)
-	fmt.Printf("%x runes as string = %s\n", rns1, str1)
+	fmt.Fprintf(w, "%x runes as string = %s\n", rns1, str1)
 	// NOTE: 5 runes become 5 character string
 
 	// --- Length of string ---
-	fmt.Printf("len() of string %s = %d\n", str1, len(str1))
+	fmt.Fprintf(w, "len() of string %s = %d\n", str1, len(str1))
 	// Oops! - len(Señor) gives 6
 	/*
 		'len' gives the number of bytes, which
@@ -613,10 +696,11 @@ func main() {
 		with utf8.RuneCountInString() function
 		for this we have to import unicode/utf8
 	*/
-	fmt.Printf("RuneCountInString() of string %s = %d\n", str1, utf8.RuneCountInString(str1))
+	fmt.Fprintf(w, "RuneCountInString() of string %s = %d\n", str1, utf8.RuneCountInString(str1))
 	// --- Strings are immutable ---
 	str2 := "abcd"
-	// str2[0] := "A" // This will give a compiler error
+	// str2[0] = 'A' // This will give a compiler error
+	// see 'gonutshell gallery strings'
 	/*
 		Like most other programming languages string instances
 		are immutable in Go. This allows string pool optimizations
@@ -629,8 +713,10 @@ func main() {
 	rns2 := []rune(str2)
 	rns2[0] = 'A'
 	str3 := string(rns2)
-	fmt.Printf("modified %s to %s\n", str2, str3)
+	fmt.Fprintf(w, "modified %s to %s\n", str2, str3)
+}
 
+func lessonPointers(w io.Writer) {
 	// ==== Pointers ====
 	/*
 		A pointer is a variable that can hold the address
@@ -645,13 +731,534 @@ func main() {
 	myI1 := 23
 	var p1 *int
 	p1 = &myI1
-	fmt.Printf("Value of pointer p1 = %p\n", p1)
+	fmt.Fprintf(w, "Value of pointer p1 = %p\n", p1)
 	var p2 *string
-	fmt.Printf("Value of pointer p2 = %p\n", p2) // 0x0 or nil
+	fmt.Fprintf(w, "Value of pointer p2 = %p\n", p2) // Output: Value of pointer p2 = 0x0
 	if p2 == nil {
-		fmt.Printf("Unassigned pointer p2 is nil")
+		fmt.Fprintf(w, "Unassigned pointer p2 is nil\n")
+	}
+}
+
+func lessonStructs(w io.Writer) {
+	// ==== Structs ====
+	/*
+		A struct is a sequence of named fields, each of a type of its own.
+		Declaring a struct type gives a name to that layout, the way
+		'type counter func() int' gives a name to a function type.
+		The type 'point' used below is declared at the end of this file:
+		>> type point struct {
+		>>     X, Y int
+		>> }
+	*/
+	// --- struct literals
+	p1 := point{1, 2}           // the fields in order
+	p2 := point{Y: 5}           // by name, the others are zero
+	var p3 point                // the zero value, every field is zero
+	fmt.Fprintln(w, p1, p2, p3) // Output: {1 2} {0 5} {0 0}
+	fmt.Fprintf(w, "%+v\n", p1) // Output: {X:1 Y:2}
+	pp := &point{X: 3}          // a pointer to a new point
+	pp.Y = 4                    // short for (*pp).Y = 4
+	fmt.Fprintln(w, *pp)        // Output: {3 4}
+	anon := struct {
+		Name string
+		Age  int
+	}{"Ada", 36}
+	fmt.Fprintf(w, "%+v\n", anon) // Output: {Name:Ada Age:36}
+	/*
+		NOTE: literals of struct types of other packages should name the
+		fields; positional ones break as soon as a field is added.
+	*/
+
+	// --- field tags
+	type user struct {
+		Name  string `json:"name"`
+		Email string `json:"email,omitempty"`
+		age   int    // not exported, encoding/json cannot see it
 	}
+	b, _ := json.Marshal(user{Name: "Ada", age: 36})
+	fmt.Fprintln(w, string(b)) // Output: {"name":"Ada"}
+	f, _ := reflect.TypeFor[user]().FieldByName("Email")
+	fmt.Fprintf(w, "tag %s, json key %q\n", f.Tag, f.Tag.Get("json"))
+	// Output: tag json:"email,omitempty", json key "email,omitempty"
+	/*
+		A tag is a string attached to a field. It means nothing to the
+		language; packages such as encoding/json read it with reflect.
+		Fields whose names start with a lower case letter are not exported
+		and the other packages, encoding/json included, leave them out.
+	*/
+
+	// --- structs are value types
+	q1 := p1
+	q1.X = 100
+	fmt.Fprintln(w, "p1 =", p1, "q1 =", q1) // Output: p1 = {1 2} q1 = {100 2}
+	// NOTE: like 'b5 := a5' in the Arrays lesson, q1 is a copy of every field
+	r1 := &p1
+	r1.X = 100
+	fmt.Fprintln(w, "p1 =", p1) // Output: p1 = {100 2}
+	// NOTE: r1 points to p1 itself, as p1 = &myI1 did in the Pointers lesson
+	fmt.Fprintln(w, p1 == q1, p2 == point{0, 5}) // Output: true true
+	/*
+		Structs are compared field by field, with == and !=, when all of
+		their fields can be compared. A struct with a slice or a map field
+		cannot, see 'gonutshell gallery struct-compare'.
+	*/
+
+	// --- copies share what their fields point to
+	type team struct {
+		Name    string
+		Members []string
+	}
+	t1 := team{"gophers", []string{"Ann", "Bob"}}
+	t2 := t1
+	t2.Name = "gophers 2"
+	t2.Members[0] = "Zoe"
+	fmt.Fprintln(w, t1) // Output: {gophers [Zoe Bob]}
+	/*
+		t2 has a Name of its own, but copying Members copied a slice header
+		that points to the same backing array, see the Slice internals
+		lesson. A deep copy has to copy the slice as well:
+		>> t2.Members = slices.Clone(t1.Members)
+	*/
+
+	// ==== Methods ====
+	/*
+		A method is a function with a receiver, written before its name.
+		The methods of point are declared along with the type:
+		>> func (p point) Scaled(k int) point // value receiver
+		>> func (p *point) Move(dx, dy int)   // pointer receiver
+	*/
+	// --- value receivers
+	p4 := point{1, 2}
+	fmt.Fprintln(w, p4.Scaled(10), p4) // Output: {10 20} {1 2}
+	// NOTE: Scaled works on a copy of p4, so p4 is left as it was
+
+	// --- pointer receivers
+	p4.Move(5, 5)
+	fmt.Fprintln(w, p4)           // Output: {6 7}
+	fmt.Fprintln(w, pp.Scaled(2)) // Output: {6 8}
+	/*
+		Move has a pointer receiver, so it changes the point it is called
+		on. 'p4.Move(5, 5)' is short for '(&p4).Move(5, 5)': Go takes the
+		address of p4, as 'p1 = &myI1' did by hand in the Pointers lesson.
+		The other way round, 'pp.Scaled(2)' is short for '(*pp).Scaled(2)'.
+	*/
+
+	// --- method sets
+	move := (*point).Move // method expression, the receiver is the first argument
+	move(&p4, 1, 1)
+	scale := p4.Scaled // method value, bound to a copy of p4
+	p4.X = 0
+	fmt.Fprintln(w, p4, scale(2)) // Output: {0 8} {14 16}
+	/*
+		The method set of point is {Scaled}, that of *point is {Scaled,
+		Move}. Calling Move on a point works only when Go can take its
+		address: a variable has one, a map element has not, see
+		'gonutshell gallery unaddressable'. Method sets also decide which
+		types satisfy an interface.
+	*/
 
+	// ==== Embedding ====
+	/*
+		A field declared with a type and no name is embedded. The fields and
+		methods of the embedded type are promoted, they can be used as if
+		the outer type declared them:
+		>> type circle struct {
+		>>     point // embedded, the name of the field is point
+		>>     Radius int
+		>> }
+	*/
+	// --- promoted fields and methods
+	c := circle{point{1, 1}, 3}
+	c.Move(1, 2)                       // promoted from *point, moves c.point
+	fmt.Fprintln(w, c.X, c.Y, c.point) // Output: 2 3 {2 3}
+
+	// --- shadowing
+	fmt.Fprintf(w, "%+v\n", c.Scaled(2)) // Output: {point:{X:4 Y:6} Radius:6}
+	fmt.Fprintln(w, c.point.Scaled(2))   // Output: {4 6}
+	/*
+		circle declares a Scaled method of its own, which scales the radius
+		too. It shadows the promoted one, which is still there as
+		c.point.Scaled. Embedding is not inheritance: a circle is not a
+		point and cannot be passed where a point is expected, c.point can.
+	*/
+}
+
+func lessonInterfaces(w io.Writer) {
+	// ==== Interfaces ====
+	/*
+		An interface type is a set of methods. A value of any type that
+		has those methods can be used as a value of the interface type;
+		there is no 'implements' to write, the compiler checks the methods.
+	*/
+	// --- implicit satisfaction
+	type mover interface {
+		Move(dx, dy int)
+	}
+	var m mover = &point{1, 2} // *point has a Move method
+	m.Move(1, 1)
+	fmt.Fprintln(w, m)          // Output: &{2 3}
+	m = &circle{point{0, 0}, 5} // Move is promoted from point
+	m.Move(3, 4)
+	fmt.Fprintf(w, "%T %v\n", m, m) // Output: *main.circle &{{3 4} 5}
+	/*
+		An interface value holds a value of some other type, its dynamic
+		type, and calls the methods of that type. A point is not a mover,
+		as Move is not in the method set of point, see
+		'gonutshell gallery not-a-mover'.
+	*/
+
+	// --- the empty interface - any
+	var a any                       // same as interface{}: no methods, every type has them
+	fmt.Fprintf(w, "%v %T\n", a, a) // Output: <nil> <nil>
+	a = 42
+	fmt.Fprintf(w, "%v %T\n", a, a) // Output: 42 int
+	a = []string{"go"}
+	fmt.Fprintf(w, "%v %T\n", a, a) // Output: [go] []string
+	/*
+		This is how fmt.Printf takes arguments of any type, as promised in
+		the Variadic lesson:
+		>> func Printf(format string, a ...any) (n int, err error)
+		Each argument arrives in a slice of any, along with its type.
+	*/
+
+	// --- type assertions - comma ok
+	a = 42
+	n, ok := a.(int)
+	fmt.Fprintf(w, "n = %d; ok = %v\n", n, ok) // Output: n = 42; ok = true
+	s, ok := a.(string)
+	fmt.Fprintf(w, "s = %q; ok = %v\n", s, ok) // Output: s = ""; ok = false
+	/*
+		a.(T) gets the value of type T out of the interface value a. With
+		a second result it reports whether a holds a T, and gives the zero
+		value of T when it does not, like 'sr, found = scores["Bob"]' in
+		the Maps lesson. Without it, a failed assertion panics.
+	*/
+	mv, ok := a.(mover)     // T may be an interface: does a have a Move method?
+	fmt.Fprintln(w, mv, ok) // Output: <nil> false
+
+	// --- type switches
+	fmt.Fprintln(w, describe(7))          // Output: int 7, doubled 14
+	fmt.Fprintln(w, describe("seven"))    // Output: string "seven" of 5 bytes
+	fmt.Fprintln(w, describe([]int{7}))   // Output: []int of length 1
+	fmt.Fprintln(w, describe(celsius(7))) // Output: fmt.Stringer 7.0°C
+	fmt.Fprintln(w, describe(7.5))        // Output: float64 7.5
+	fmt.Fprintln(w, describe(nil))        // Output: nil
+	/*
+		A type switch is a chain of type assertions: 'switch x := v.(type)'
+		picks the first case that v holds, and within it x has the type of
+		the case. See describe at the end of this file.
+	*/
+
+	// --- nil interface vs nil pointer
+	var p *point
+	m = p
+	fmt.Fprintln(w, p == nil, m == nil) // Output: true false
+	/*
+		An interface value is nil only when it holds nothing at all. m holds
+		a nil *point: its dynamic type is *point, so m is not nil, even if
+		the pointer in it is, as p2 was in the Pointers lesson. Functions
+		returning an interface, such as error, should return a literal nil
+		rather than a nil pointer of some type.
+	*/
+
+	// ==== fmt.Stringer & fmt.Formatter ====
+	// --- fmt.Stringer
+	temp := celsius(21.5)
+	fmt.Fprintln(w, temp)                            // Output: 21.5°C
+	fmt.Fprintf(w, "%v %s %.0f\n", temp, temp, temp) // Output: 21.5°C 21.5°C 22
+	/*
+		fmt checks whether a value satisfies fmt.Stringer, the interface
+		with a 'String() string' method, and prints what String returns for
+		%v and %s. celsius declares one; the other verbs, such as %.0f,
+		still format the number.
+	*/
+
+	// --- fmt.Formatter
+	price := money(1999)
+	fmt.Fprintf(w, "%v | %d | %8v | %x\n", price, price, price, price)
+	// Output: $19.99 | 1999 |   $19.99 | %!x(money=1999)
+	/*
+		A type with a Format method, the fmt.Formatter interface, does all
+		of its formatting itself, for every verb. money handles %v, %s and
+		%d, the width of any, and reports the other verbs the way fmt does.
+	*/
+
+	// ==== A mini Printf ====
+	miniPrintf(w, "%s is %d, %v and %v\n", "gopher", 13, true, celsius(36.6))
+	// Output: gopher is 13, true and 36.6°C
+	miniPrintf(w, "%d%% of %s, %T %q\n", 50, []int{1, 2}, 3.5, "done")
+	// Output: 50% of [%!s(int=1) %!s(int=2)], float64 "done"
+	miniPrintf(w, "%v%", 7, "left over")
+	fmt.Fprintln(w)
+	// Output: 7%!(NOVERB)%!(EXTRA string=left over)
+	/*
+		miniPrintf, at the end of this file, walks through the format and
+		formats each argument with a type switch on its dynamic type, the
+		way fmt.Printf does with reflection for the types it cannot list.
+		A verb that does not apply, a missing argument, a '%' without a
+		verb and arguments left over are reported in fmt's own words. Like
+		fmt, it applies the verb to each element of a slice.
+	*/
+}
+
+func lessonErrors(w io.Writer) {
+	// ==== Errors are values ====
+	/*
+		Go has no exceptions. A function that can fail returns an error as
+		its last result, and the caller checks it right away. 'error' is an
+		interface with a single method:
+		>> type error interface { Error() string }
+		A nil error means success, and the other results are then valid.
+	*/
+	// --- returning an error
+	sum := func(a, b int) int { return a + b }
+	total, err := checkedReduce([]int{1, 2, 3}, sum)
+	fmt.Fprintln(w, total, err) // Output: 6 <nil>
+	_, err = checkedReduce(nil, sum)
+	if err != nil {
+		fmt.Fprintln(w, "error:", err) // Output: error: empty slice
+	}
+	/*
+		ireduce of the First-class functions lesson returns 0 for an empty
+		slice, the same as for []int{0}. checkedReduce, declared at the end
+		of this file, tells the two apart with an error.
+	*/
+
+	// --- wrapping - fmt.Errorf & errors.Is
+	err = fmt.Errorf("total of scores: %w", err)
+	fmt.Fprintln(w, err)                                       // Output: total of scores: empty slice
+	fmt.Fprintln(w, err == errEmpty, errors.Is(err, errEmpty)) // Output: false true
+	/*
+		%w formats an error like %v and also wraps it: the new error adds
+		context but keeps the original one inside. errors.Is looks for a
+		given error along the chain of wrapped errors, where == only
+		compares the outer one. Errors such as errEmpty, declared once and
+		compared with errors.Is, are called sentinel errors.
+	*/
+
+	// --- custom error types - errors.As
+	s := []int{1, 2, 3, 4, 5}
+	s, err = safeDelete(s, 1)
+	fmt.Fprintln(w, s, err) // Output: [1 3 4 5] <nil>
+	_, err = safeDelete(s, 7)
+	var ie *indexError
+	if errors.As(err, &ie) {
+		fmt.Fprintf(w, "index %d of %d: %v\n", ie.Index, ie.Len, err)
+	}
+	// Output: index 7 of 4: index 7 out of range [0:4]
+	/*
+		Any type with an Error method is an error. A struct can carry the
+		details of what went wrong, and errors.As finds an error of that
+		type along the chain and sets ie to it. safeDelete is the delete
+		of the Slices lesson checking its index first.
+	*/
+
+	// --- errors.Join
+	err = errors.Join(errEmpty, &indexError{9, 4})
+	fmt.Fprintf(w, "%q\n", err)                                    // Output: "empty slice\nindex 9 out of range [0:4]"
+	fmt.Fprintln(w, errors.Is(err, errEmpty), errors.As(err, &ie)) // Output: true true
+	// NOTE: a joined error wraps all of its errors, one per line
+
+	// ==== defer, panic & recover ====
+	// --- defer ordering
+	countdown(w) // Output: counting down: 2 1 0
+	/*
+		A deferred call runs when the function it was deferred in returns,
+		and the deferred calls run in the reverse order of the defers, last
+		in first out. The arguments are evaluated at the defer itself,
+		which is why each call prints the i of its own iteration. defer is
+		mostly used to clean up, as in
+		>> f, err := os.Open(name)
+		>> if err != nil { return err }
+		>> defer f.Close()
+	*/
+
+	// --- panic & recover
+	_, err = recoveredDelete([]int{1, 2}, 5)
+	fmt.Fprintln(w, err) // Output: delete 5: runtime error: slice bounds out of range [5:2]
+	var re runtime.Error
+	fmt.Fprintln(w, errors.As(err, &re)) // Output: true
+	/*
+		An index out of range is a bug rather than an error the caller can
+		handle, so Go panics: the function stops, its deferred calls run,
+		then those of its callers, up to the end of the program. A deferred
+		function can stop that with recover, which returns the value the
+		panic was called with. recoveredDelete turns the panic into an
+		error that way, setting its named result err.
+		NOTE: recover only works in a deferred function, and is best kept
+		for turning a panic into an error at the edge of a package.
+	*/
+}
+
+func lessonConcurrency(w io.Writer) {
+	// ==== Goroutines & channels ====
+	/*
+		'go f(x)' calls f in a new goroutine, a function running at the
+		same time as the rest of the program; the go statement returns
+		right away. Goroutines talk through channels, typed pipes that a
+		value is sent on with 'ch <- v' and received from with '<-ch'.
+		NOTE: the goroutines of this lesson send what they find back on
+		channels and the lesson prints it, so the output comes out in the
+		same order on every run.
+	*/
+	// --- goroutines & unbuffered channels
+	done := make(chan string) // unbuffered: a send waits for a receiver
+	go func() {
+		done <- "hello from a goroutine"
+	}()
+	fmt.Fprintln(w, <-done) // Output: hello from a goroutine
+	/*
+		The receive waits for the goroutine to send, and the send waits for
+		the receive: an unbuffered channel is a meeting point, which also
+		makes everything the goroutine did before the send visible after
+		the receive.
+	*/
+
+	// --- buffered channels
+	queue := make(chan int, 3) // room for 3 values
+	queue <- 1
+	queue <- 2                              // no receiver needed while there is room
+	fmt.Fprintln(w, len(queue), cap(queue)) // Output: 2 3
+	fmt.Fprintln(w, <-queue, <-queue)       // Output: 1 2
+	// NOTE: a send on a full channel, or a receive on an empty one, waits
+
+	// --- range over a channel & close
+	squares := make(chan int)
+	go func() {
+		defer close(squares) // no more values: ends the range below
+		for i := 1; i <= 4; i++ {
+			squares <- i * i
+		}
+	}()
+	var got []int
+	for sq := range squares {
+		got = append(got, sq)
+	}
+	fmt.Fprintln(w, got) // Output: [1 4 9 16]
+	v, ok := <-squares
+	fmt.Fprintln(w, v, ok) // Output: 0 false
+	/*
+		Ranging over a channel receives until the channel is closed. A
+		receive from a closed channel does not wait, it gives the zero
+		value and, with comma ok, false. Only the sender should close a
+		channel: sending on a closed one panics.
+	*/
+
+	// ==== select ====
+	// --- select with timeouts
+	start := make(chan struct{})
+	reply := make(chan string, 1)
+	go func() {
+		<-start // a slow worker, which only starts once we stopped waiting
+		reply <- "late reply"
+	}()
+	select {
+	case r := <-reply:
+		fmt.Fprintln(w, r)
+	case <-time.After(20 * time.Millisecond):
+		fmt.Fprintln(w, "timed out") // Output: timed out
+	}
+	close(start)
+	fmt.Fprintln(w, <-reply) // Output: late reply
+	/*
+		select waits for the first of its cases that can go ahead, here a
+		reply or the value time.After sends once the time is up. With
+		several ready it picks one at random. The buffer of one lets the
+		worker send its late reply even if nobody was waiting any more, so
+		it does not block forever: a goroutine stuck on a channel is never
+		freed, it leaks.
+	*/
+	select {
+	case r := <-reply:
+		fmt.Fprintln(w, r)
+	default:
+		fmt.Fprintln(w, "nothing to receive") // Output: nothing to receive
+	}
+	// NOTE: with a default case select does not wait at all
+
+	// ==== sync ====
+	// --- sync.WaitGroup
+	var wg sync.WaitGroup
+	lengths := make([]int, 3)
+	for i, word := range []string{"go", "gopher", "goroutine"} {
+		wg.Go(func() {
+			lengths[i] = len(word) // each goroutine sets an element of its own
+		})
+	}
+	wg.Wait()
+	fmt.Fprintln(w, lengths) // Output: [2 6 9]
+	/*
+		wg.Go runs a function in a goroutine and counts it, wg.Wait waits
+		until all of them have returned. Older code does the counting with
+		wg.Add(1) before the go statement and 'defer wg.Done()' in the
+		goroutine.
+	*/
+
+	// --- sync.Mutex
+	var mu sync.Mutex
+	hits := map[string]int{}
+	for range 100 {
+		wg.Go(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			hits["home"]++
+		})
+	}
+	wg.Wait()
+	fmt.Fprintln(w, hits["home"]) // Output: 100
+	/*
+		Goroutines that change the same variable have to take turns, or
+		some increments get lost, and writing a map concurrently can even
+		crash the program. A mutex lets one goroutine at a time between
+		Lock and Unlock. 'go run -race' and 'go test -race' report such
+		data races when the program runs into one.
+	*/
+
+	// ==== A worker pool ====
+	/*
+		The sum of doubles of the First-class functions lesson, with the
+		numbers split into chunks and each chunk summed by one of three
+		workers, imap and ireduce and all.
+	*/
+	// --- jobs & results
+	type job struct {
+		id   int
+		nums []int
+	}
+	type result struct{ id, sum int }
+	nh := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	double := func(i int) int { return i * 2 }
+	add := func(x, y int) int { return x + y }
+	jobs, results := make(chan job), make(chan result)
+	var workers sync.WaitGroup
+	for range 3 {
+		workers.Go(func() {
+			for j := range jobs {
+				results <- result{j.id, ireduce(imap(j.nums, double), add)}
+			}
+		})
+	}
+	go func() {
+		id := 0
+		for chunk := range slices.Chunk(nh, 4) {
+			jobs <- job{id, chunk}
+			id++
+		}
+		close(jobs) // the workers' range loops end
+		workers.Wait()
+		close(results) // the range loop below ends
+	}()
+	partial := make([]int, (len(nh)+3)/4)
+	for r := range results {
+		partial[r.id] = r.sum
+	}
+	fmt.Fprintln(w, "partial sums:", partial)                      // Output: partial sums: [20 52 38]
+	fmt.Fprintf(w, "Sum of doubles = %d\n", ireduce(partial, add)) // Output: Sum of doubles = 110
+	/*
+		The results arrive in whatever order the workers finish. Each one
+		carries the id of its job, so that it goes to its own place in
+		partial and the output is the same on every run.
+	*/
 }
 
 // ==== Function declaration ====
@@ -660,8 +1267,8 @@ func main() {
 	Like other lamguages, they take paramters in parantheses '()',
 	and return values using 'return' keyword.
 */
-func printDouble(x int) {
-	fmt.Printf("Double of %d = %d\n", x, 2*x)
+func printDouble(w io.Writer, x int) {
+	fmt.Fprintf(w, "Double of %d = %d\n", x, 2*x)
 }
 
 /*
@@ -675,7 +1282,8 @@ func triple(x int) int {
 /*
 Go functions can return more than one value, in fact it
 is idiomatic in Go to use this for returning 'errors'
-from functions as there are no exceptions.
+from functions as there are no exceptions, see lessonErrors
+and checkedReduce.
 */
 func sumAndProd(x, y int) (int, int) {
 	return x + y, x * y
@@ -712,7 +1320,7 @@ This will imply that the function can take any number of
 arguments of the type 'T'
 
 For "variable type variadic parameters" (such as used by Println, Printf etc.),
-we have to rely on the empty interface 'interface{}'. More on this later!
+we have to rely on the empty interface 'interface{}', see lessonInterfaces.
 */
 func fullName(names ...string) string {
 	var buffer bytes.Buffer
@@ -727,8 +1335,8 @@ func fullName(names ...string) string {
 }
 
 // show variadic argumenyt type
-func showVar(prm ...int) {
-	fmt.Printf("Type of varidic argument 'prm' = %T\n", prm)
+func showVar(w io.Writer, prm ...int) {
+	fmt.Fprintf(w, "Type of varidic argument 'prm' = %T\n", prm)
 	// prm becomes a new Slice within the function
 }
 
@@ -779,3 +1387,242 @@ func ireduce(s []int, f func(int, int) int) int {
 	}
 	return r
 }
+
+// ==== Struct types and methods ====
+// --- point is a struct type with two fields ---
+type point struct {
+	X, Y int
+}
+
+// --- value receiver - p is a copy of the point Scaled is called on ---
+func (p point) Scaled(k int) point {
+	p.X *= k
+	p.Y *= k
+	return p
+}
+
+// --- pointer receiver - p points to the point Move is called on ---
+func (p *point) Move(dx, dy int) {
+	p.X += dx
+	p.Y += dy
+}
+
+// --- circle embeds point, whose fields and methods it promotes ---
+type circle struct {
+	point
+	Radius int
+}
+
+// Scaled shadows the method promoted from point.
+func (c circle) Scaled(k int) circle {
+	c.point = c.point.Scaled(k)
+	c.Radius *= k
+	return c
+}
+
+// ==== Interfaces ====
+// --- describe tells the dynamic type of v with a type switch ---
+func describe(v any) string {
+	switch x := v.(type) {
+	case int:
+		return fmt.Sprintf("int %d, doubled %d", x, 2*x)
+	case string:
+		return fmt.Sprintf("string %q of %d bytes", x, len(x))
+	case []int:
+		return fmt.Sprintf("[]int of length %d", len(x))
+	case fmt.Stringer: // any type with a String method
+		return "fmt.Stringer " + x.String()
+	case nil:
+		return "nil"
+	default: // x has the type of v, any
+		return fmt.Sprintf("%T %v", x, x)
+	}
+}
+
+// --- celsius is a fmt.Stringer ---
+type celsius float64
+
+func (c celsius) String() string {
+	return fmt.Sprintf("%.1f°C", float64(c))
+}
+
+// --- money is a fmt.Formatter, an amount in cents ---
+type money int64
+
+func (m money) Format(f fmt.State, verb rune) {
+	var s string
+	switch verb {
+	case 'v', 's':
+		s = fmt.Sprintf("$%d.%02d", m/100, m%100)
+	case 'd':
+		s = strconv.FormatInt(int64(m), 10)
+	default:
+		fmt.Fprintf(f, "%%!%c(money=%d)", verb, int64(m))
+		return
+	}
+	if width, ok := f.Width(); ok && width > len(s) {
+		s = strings.Repeat(" ", width-len(s)) + s
+	}
+	io.WriteString(f, s)
+}
+
+// --- miniPrintf - Printf for %v, %d, %s, %q, %T and %% ---
+func miniPrintf(w io.Writer, format string, args ...any) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			b.WriteString("%!(NOVERB)") // a '%' with no verb after it
+			break
+		}
+		i++
+		verb := format[i]
+		if verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		if len(args) == 0 {
+			b.WriteString("%!" + string(verb) + "(MISSING)")
+			continue
+		}
+		b.WriteString(miniFormat(verb, args[0]))
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		// the arguments no verb used are listed, as fmt does
+		extra := make([]string, len(args))
+		for i, arg := range args {
+			extra[i] = badArg(arg)
+		}
+		b.WriteString("%!(EXTRA " + strings.Join(extra, ", ") + ")")
+	}
+	io.WriteString(w, b.String())
+}
+
+// miniFormat formats arg for verb by its dynamic type.
+func miniFormat(verb byte, arg any) string {
+	switch {
+	case arg == nil && (verb == 'T' || verb == 'v'):
+		return "<nil>" // a nil interface has no dynamic type to reflect on
+	case verb == 'T':
+		return reflect.TypeOf(arg).String()
+	}
+	switch x := arg.(type) {
+	case fmt.Stringer:
+		if verb == 'v' || verb == 's' {
+			return x.String()
+		}
+	case string:
+		switch verb {
+		case 'v', 's':
+			return x
+		case 'q':
+			return strconv.Quote(x)
+		}
+	case int:
+		if verb == 'v' || verb == 'd' {
+			return strconv.Itoa(x)
+		}
+	case bool:
+		if verb == 'v' {
+			return strconv.FormatBool(x)
+		}
+	case float64:
+		if verb == 'v' {
+			return strconv.FormatFloat(x, 'g', -1, 64)
+		}
+	}
+	v := reflect.ValueOf(arg)
+	switch {
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		// fmt applies the verb to each element
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = miniFormat(verb, v.Index(i).Interface())
+		}
+		return "[" + strings.Join(elems, " ") + "]"
+	case arg == nil:
+		return "%!" + string(verb) + "(<nil>)"
+	}
+	return fmt.Sprintf("%%!%c(%T=%s)", verb, arg, plainValue(v))
+}
+
+// plainValue formats v as %v does, without the String method v may have:
+// fmt leaves methods alone while it reports a bad verb.
+func plainValue(v reflect.Value) string {
+	switch {
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10)
+	case v.CanFloat():
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case v.Kind() == reflect.String:
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// badArg describes an argument left over, by its type and value.
+func badArg(arg any) string {
+	if arg == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%T=%v", arg, arg)
+}
+
+// ==== Errors ====
+// --- errEmpty is a sentinel error, checked with errors.Is ---
+var errEmpty = errors.New("empty slice")
+
+// --- checkedReduce is ireduce failing on an empty slice ---
+func checkedReduce(s []int, f func(int, int) int) (int, error) {
+	if len(s) == 0 {
+		return 0, errEmpty
+	}
+	return ireduce(s, f), nil
+}
+
+// --- indexError is a custom error type, found with errors.As ---
+type indexError struct {
+	Index, Len int
+}
+
+func (e *indexError) Error() string {
+	return fmt.Sprintf("index %d out of range [0:%d]", e.Index, e.Len)
+}
+
+// --- safeDelete deletes s[i], keeping the order, if there is one ---
+func safeDelete(s []int, i int) ([]int, error) {
+	if i < 0 || i >= len(s) {
+		return s, &indexError{i, len(s)}
+	}
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = 0
+	return s[:len(s)-1], nil
+}
+
+// --- countdown prints from its deferred calls ---
+func countdown(w io.Writer) {
+	defer fmt.Fprintln(w)
+	for i := range 3 {
+		defer fmt.Fprint(w, " ", i)
+	}
+	fmt.Fprint(w, "counting down:")
+}
+
+// --- recoveredDelete deletes without checking, and recovers ---
+func recoveredDelete(s []int, i int) (r []int, err error) {
+	defer func() {
+		switch v := recover().(type) {
+		case nil: // no panic
+		case error: // such as the runtime.Error of an index out of range
+			err = fmt.Errorf("delete %d: %w", i, v)
+		default:
+			err = fmt.Errorf("delete %d: %v", i, v)
+		}
+	}()
+	copy(s[i:], s[i+1:]) // panics when i is out of range
+	return s[:len(s)-1], nil
+}
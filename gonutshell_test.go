@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMiniPrintf(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		args   []any
+	}{
+		{"%v %d %s %q", []any{1.5, 42, "go", "go"}},
+		{"%T %T %T", []any{42, "go", []int{1}}},
+		{"%T %v %s", []any{nil, nil, nil}},
+		{"%v %s", []any{celsius(21.5), celsius(-3)}},
+		{"100%% %d %d", []any{1}},
+		{"%s and %d", []any{[]int{1, 2}, "go"}},
+		{"%d", []any{celsius(21.5)}},
+		{"%d %v", []any{[]int{1, 2}, [2]string{"a", "b"}}},
+		{"%s", []any{nil}},
+		{"%v%", []any{7}},
+		{"%d", []any{1, "go", nil, 2.5}},
+		{"%", nil},
+	} {
+		var b strings.Builder
+		miniPrintf(&b, tt.format, tt.args...)
+		if want := fmt.Sprintf(tt.format, tt.args...); b.String() != want {
+			t.Errorf("miniPrintf(%q, %v) = %q, want %q as fmt", tt.format, tt.args, b.String(), want)
+		}
+	}
+}
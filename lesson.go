@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Lesson is one named topic of the tour that can be run on its own.
+type Lesson struct {
+	ID    string // short name used on the command line, e.g. "slices"
+	Title string
+	Run   func(w io.Writer)
+
+	Difficulty difficulty
+	Tags       []string // topics covered, which 'gonutshell path' accepts too
+	Requires   []string // IDs of the lessons this one builds on
+	Minutes    int      // estimated time to go through it
+
+	pack *packLesson // for lessons of packs, which have no source in the tour
+}
+
+// difficulty is how hard a lesson is for someone new to Go.
+type difficulty int
+
+const (
+	beginner difficulty = iota
+	intermediate
+	advanced
+)
+
+func (d difficulty) String() string {
+	switch d {
+	case beginner:
+		return "beginner"
+	case intermediate:
+		return "intermediate"
+	case advanced:
+		return "advanced"
+	}
+	return fmt.Sprintf("difficulty(%d)", int(d))
+}
+
+// UnmarshalText reads a difficulty as String writes it, for pack manifests.
+func (d *difficulty) UnmarshalText(text []byte) error {
+	for v := beginner; v <= advanced; v++ {
+		if v.String() == string(text) {
+			*d = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown difficulty %q, not beginner, intermediate or advanced", text)
+}
+
+// lessons is the registry of the tour, in the order it is meant to be read.
+// That order has to agree with the prerequisites, see curriculum.
+var lessons = []Lesson{
+	{
+		ID: "variables", Title: "Variables & constants", Run: lessonVariables,
+		Difficulty: beginner, Tags: []string{"basics", "types", "constants"}, Minutes: 10,
+	},
+	{
+		ID: "printing", Title: "Formatted printing", Run: lessonPrinting,
+		Difficulty: beginner, Tags: []string{"fmt", "verbs"}, Requires: []string{"variables"}, Minutes: 10,
+	},
+	{
+		ID: "functions", Title: "Functions", Run: lessonFunctions,
+		Difficulty: beginner, Tags: []string{"functions"}, Requires: []string{"variables"}, Minutes: 10,
+	},
+	{
+		ID: "conversions", Title: "Type conversions", Run: lessonConversions,
+		Difficulty: beginner, Tags: []string{"types", "conversions"}, Requires: []string{"variables"}, Minutes: 5,
+	},
+	{
+		ID: "arrays", Title: "Arrays", Run: lessonArrays,
+		Difficulty: beginner, Tags: []string{"collections", "arrays"}, Requires: []string{"variables"}, Minutes: 10,
+	},
+	{
+		ID: "slices", Title: "Slices", Run: lessonSlices,
+		Difficulty: intermediate, Tags: []string{"collections", "slices", "append", "copy"}, Requires: []string{"arrays"}, Minutes: 20,
+	},
+	{
+		ID: "slice-internals", Title: "Slice headers & backing arrays", Run: lessonSliceInternals,
+		Difficulty: intermediate, Tags: []string{"slices", "append", "aliasing", "memory"}, Requires: []string{"slices"}, Minutes: 15,
+	},
+	{
+		ID: "maps", Title: "Maps", Run: lessonMaps,
+		Difficulty: beginner, Tags: []string{"collections", "maps"}, Requires: []string{"variables"}, Minutes: 10,
+	},
+	{
+		ID: "control-flow", Title: "Control-flow", Run: lessonControlFlow,
+		Difficulty: beginner, Tags: []string{"if", "switch", "loops", "range"}, Requires: []string{"slices", "maps"}, Minutes: 15,
+	},
+	{
+		ID: "placeholder", Title: "Place-holder identifier", Run: lessonPlaceholder,
+		Difficulty: beginner, Tags: []string{"blank identifier"}, Requires: []string{"functions", "control-flow"}, Minutes: 5,
+	},
+	{
+		ID: "variadic", Title: "Variadic functions", Run: lessonVariadic,
+		Difficulty: intermediate, Tags: []string{"functions", "variadic"}, Requires: []string{"functions", "slices"}, Minutes: 10,
+	},
+	{
+		ID: "first-class", Title: "First-class functions", Run: lessonFirstClass,
+		Difficulty: intermediate, Tags: []string{"functions", "closures", "function literals"}, Requires: []string{"functions", "control-flow"}, Minutes: 15,
+	},
+	{
+		ID: "strings", Title: "Advanced strings", Run: lessonStrings,
+		Difficulty: intermediate, Tags: []string{"strings", "runes", "unicode", "bytes"}, Requires: []string{"conversions", "slices", "control-flow"}, Minutes: 15,
+	},
+	{
+		ID: "pointers", Title: "Pointers", Run: lessonPointers,
+		Difficulty: intermediate, Tags: []string{"pointers"}, Requires: []string{"functions"}, Minutes: 10,
+	},
+	{
+		ID: "structs", Title: "Structs, methods & embedding", Run: lessonStructs,
+		Difficulty: intermediate, Tags: []string{"structs", "methods", "embedding", "types"}, Requires: []string{"pointers", "arrays"}, Minutes: 20,
+	},
+	{
+		ID: "interfaces", Title: "Interfaces", Run: lessonInterfaces,
+		Difficulty: intermediate, Tags: []string{"interfaces", "types", "fmt"}, Requires: []string{"structs", "variadic", "maps"}, Minutes: 20,
+	},
+	{
+		ID: "errors", Title: "Errors, defer, panic & recover", Run: lessonErrors,
+		Difficulty: intermediate, Tags: []string{"errors", "defer", "panic"}, Requires: []string{"interfaces", "first-class", "slices"}, Minutes: 20,
+	},
+	{
+		ID: "concurrency", Title: "Goroutines, channels & sync", Run: lessonConcurrency,
+		Difficulty: advanced, Tags: []string{"concurrency", "goroutines", "channels", "sync"}, Requires: []string{"errors", "structs"}, Minutes: 25,
+	},
+	{
+		ID: "complexity", Title: "Complexity of slice deletes", Run: lessonComplexity,
+		Difficulty: advanced, Tags: []string{"complexity", "benchmarks", "slices"}, Requires: []string{"slices", "first-class"}, Minutes: 10,
+	},
+	{
+		ID: "counters", Title: "Counters goroutines can share", Run: lessonCounters,
+		Difficulty: advanced, Tags: []string{"concurrency", "sync", "atomic", "races"}, Requires: []string{"concurrency"}, Minutes: 15,
+	},
+}
+
+// findLesson looks up a lesson in the registry by its ID.
+func findLesson(id string) (Lesson, bool) {
+	return findIn(lessons, id)
+}
+
+// selectLessons resolves lesson IDs given on the command line, all of the
+// lessons being selected when there are none.
+func selectLessons(ids []string) ([]Lesson, error) {
+	if len(ids) == 0 {
+		return lessons, nil
+	}
+	var sel []Lesson
+	for _, id := range ids {
+		l, ok := findLesson(id)
+		if !ok {
+			return nil, fmt.Errorf("no lesson %q, see 'gonutshell list'", id)
+		}
+		sel = append(sel, l)
+	}
+	return sel, nil
+}
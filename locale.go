@@ -0,0 +1,302 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// The narrative of the tour is written in English, in the comments of the
+// lessons, and that stays the source of truth. Translations of it live in
+// message catalogs, one per language, keyed by where the text is shown:
+//
+//	title                  the title of the tour
+//	intro                  the introduction, from the package comment
+//	<lesson>#title         the title of a lesson
+//	<lesson>/<heading>     the heading of a step
+//	<lesson>/<heading>#<n> the n-th note of a step, counted from 1
+//
+// Every message records the English text it translates, so that a change
+// of the English makes it stale rather than silently wrong.
+
+// sourceLang is the language the tour is written in.
+const sourceLang = "en"
+
+// langEnv picks the language as the -lang flag.
+const langEnv = "GONUTSHELL_LANG"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalog holds the translations of the narrative into one language.
+type catalog struct {
+	Lang     string             `json:"lang"`
+	Name     string             `json:"name"` // of the language, in itself
+	Messages map[string]message `json:"messages"`
+}
+
+// message is the translation of one piece of the narrative.
+type message struct {
+	Source string `json:"source"` // the English text translated
+	Text   string `json:"text"`
+}
+
+// catalogs reads the catalogs embedded in the program, by language.
+var catalogs = sync.OnceValues(func() (map[string]*catalog, error) {
+	names, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	cs := map[string]*catalog{}
+	for _, n := range names {
+		data, err := localeFS.ReadFile(path.Join("locales", n.Name()))
+		if err != nil {
+			return nil, err
+		}
+		c := new(catalog)
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("locales/%s: %v", n.Name(), err)
+		}
+		if want := strings.TrimSuffix(n.Name(), ".json"); c.Lang != want {
+			return nil, fmt.Errorf("locales/%s: is for language %q", n.Name(), c.Lang)
+		}
+		cs[c.Lang] = c
+	}
+	return cs, nil
+})
+
+// languages returns the languages the tour can be shown in.
+func languages() []string {
+	cs, _ := catalogs()
+	langs := []string{sourceLang}
+	for lang := range cs {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// checkLang reports an error for a language the tour is not translated to.
+func checkLang(lang string) error {
+	if lang == sourceLang {
+		return nil
+	}
+	cs, err := catalogs()
+	if err != nil {
+		return err
+	}
+	if _, ok := cs[lang]; !ok {
+		return fmt.Errorf("no translation to %q, there is %s", lang, strings.Join(languages(), ", "))
+	}
+	return nil
+}
+
+// tr returns the translation of english, found under key, in the language
+// of options. English is shown where the translation is missing or stale.
+func tr(key, english string) string {
+	if options.lang == "" || options.lang == sourceLang {
+		return english
+	}
+	cs, _ := catalogs()
+	if c, ok := cs[options.lang]; ok {
+		if m, ok := c.Messages[key]; ok && m.Text != "" && m.Source == english {
+			return m.Text
+		}
+	}
+	return english
+}
+
+// lessonTitle returns the title of l in the language of options.
+func lessonTitle(l Lesson) string {
+	return tr(l.ID+"#title", l.Title)
+}
+
+// localizeSteps returns steps with their headings and notes in the language
+// of options.
+func localizeSteps(l Lesson, steps []step) []step {
+	loc := make([]step, len(steps))
+	for i, s := range steps {
+		key := l.ID + "/" + s.Heading
+		s.Heading = tr(key, s.Heading)
+		s.Notes = slices.Clone(s.Notes)
+		for j, n := range s.Notes {
+			s.Notes[j] = tr(fmt.Sprintf("%s#%d", key, j+1), n)
+		}
+		loc[i] = s
+	}
+	return loc
+}
+
+// sourceMessage is a piece of the English narrative.
+type sourceMessage struct {
+	Key, Text string
+}
+
+// sourceMessages returns the narrative of the tour in the order it is read.
+func sourceMessages(ls []Lesson) ([]sourceMessage, error) {
+	intro, err := tourIntro()
+	if err != nil {
+		return nil, err
+	}
+	msgs := []sourceMessage{{"title", tourTitle}, {"intro", intro}}
+	for _, l := range ls {
+		msgs = append(msgs, sourceMessage{l.ID + "#title", l.Title})
+		steps, err := lessonSteps(l)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range steps {
+			key := l.ID + "/" + s.Heading
+			msgs = append(msgs, sourceMessage{key, s.Heading})
+			for j, n := range s.Notes {
+				msgs = append(msgs, sourceMessage{fmt.Sprintf("%s#%d", key, j+1), n})
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, m := range msgs {
+		if seen[m.Key] {
+			return nil, fmt.Errorf("two pieces of the narrative have the key %q", m.Key)
+		}
+		seen[m.Key] = true
+	}
+	return msgs, nil
+}
+
+// catalogProblem is an entry of a catalog that does not match the English
+// narrative.
+type catalogProblem struct {
+	Key  string
+	Kind string // "untranslated", "stale" or "obsolete"
+	Old  string // for stale entries, the English that was translated
+	New  string
+}
+
+// checkCatalog compares c with the English narrative src: a message of src
+// may be untranslated or translated from English that has changed since,
+// and c may have messages for narrative that is no longer there.
+func checkCatalog(c *catalog, src []sourceMessage) []catalogProblem {
+	var probs []catalogProblem
+	keys := map[string]bool{}
+	for _, s := range src {
+		keys[s.Key] = true
+		m, ok := c.Messages[s.Key]
+		switch {
+		case !ok || m.Text == "":
+			probs = append(probs, catalogProblem{Key: s.Key, Kind: "untranslated"})
+		case m.Source != s.Text:
+			probs = append(probs, catalogProblem{Key: s.Key, Kind: "stale", Old: m.Source, New: s.Text})
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Messages)) {
+		if !keys[k] {
+			probs = append(probs, catalogProblem{Key: k, Kind: "obsolete"})
+		}
+	}
+	return probs
+}
+
+// catalogTemplate returns c with an empty message for every piece of src
+// it has no translation of, for translators to fill in.
+func catalogTemplate(c *catalog, src []sourceMessage) *catalog {
+	t := &catalog{Lang: c.Lang, Name: c.Name, Messages: map[string]message{}}
+	for _, s := range src {
+		m, ok := c.Messages[s.Key]
+		if !ok {
+			m = message{Source: s.Text}
+		}
+		t.Messages[s.Key] = m
+	}
+	return t
+}
+
+func cmdTranslations(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("translations", flag.ContinueOnError)
+	fs.SetOutput(w)
+	template := fs.Bool("template", false, "write the catalog of the language with the untranslated messages added")
+	verbose := fs.Bool("v", false, "list the untranslated messages too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cs, err := catalogs()
+	if err != nil {
+		return err
+	}
+	src, err := sourceMessages(tourLessons(lessons))
+	if err != nil {
+		return err
+	}
+
+	if *template {
+		if fs.NArg() != 1 {
+			return errors.New("usage: gonutshell translations -template <lang>")
+		}
+		c, ok := cs[fs.Arg(0)]
+		if !ok {
+			c = &catalog{Lang: fs.Arg(0)}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(catalogTemplate(c, src))
+	}
+
+	langs := fs.Args()
+	if len(langs) == 0 {
+		langs = slices.DeleteFunc(languages(), func(l string) bool { return l == sourceLang })
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "lang\ttranslated\tuntranslated\tstale\tobsolete")
+	var details strings.Builder
+	outdated := 0
+	for _, lang := range langs {
+		c, ok := cs[lang]
+		if !ok {
+			return checkLang(lang)
+		}
+		count := map[string]int{}
+		for _, p := range checkCatalog(c, src) {
+			count[p.Kind]++
+			switch {
+			case p.Kind == "stale":
+				fmt.Fprintf(&details, "\n%s: %s is stale, the English changed:\n", lang, p.Key)
+				for _, d := range diffLines(strings.Split(p.Old, "\n"), strings.Split(p.New, "\n")) {
+					fmt.Fprintf(&details, "  %c %s\n", d.op, d.text)
+				}
+			case p.Kind == "obsolete" || *verbose:
+				fmt.Fprintf(&details, "%s: %s is %s\n", lang, p.Key, p.Kind)
+			}
+		}
+		outdated += count["stale"] + count["obsolete"]
+		fmt.Fprintf(tw, "%s\t%d/%d\t%d\t%d\t%d\n", lang, len(src)-count["untranslated"]-count["stale"], len(src),
+			count["untranslated"], count["stale"], count["obsolete"])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if details.Len() > 0 {
+		fmt.Fprintf(w, "\n%s", strings.TrimLeft(details.String(), "\n"))
+	}
+	if outdated > 0 {
+		return fmt.Errorf("%d stale or obsolete messages, see 'gonutshell translations -template <lang>'", outdated)
+	}
+	return nil
+}
+
+// envLang returns the language langEnv asks for, English by default.
+func envLang() string {
+	if lang := os.Getenv(langEnv); lang != "" {
+		return lang
+	}
+	return sourceLang
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestCatalogs fails when the English narrative changed under a
+// translation; 'gonutshell translations' shows what changed.
+func TestCatalogs(t *testing.T) {
+	cs, err := catalogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := sourceMessages(lessons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for lang, c := range cs {
+		for _, p := range checkCatalog(c, src) {
+			if p.Kind != "untranslated" {
+				t.Errorf("%s: %s is %s", lang, p.Key, p.Kind)
+			}
+		}
+	}
+
+	c := &catalog{Messages: map[string]message{
+		"title":      {Source: tourTitle, Text: "Go en pocas palabras"},
+		"intro":      {Source: "An old introduction", Text: "Una introducción antigua"},
+		"gone#title": {Source: "Gone", Text: "Ido"},
+	}}
+	kinds := map[string]string{}
+	for _, p := range checkCatalog(c, src) {
+		kinds[p.Key] = p.Kind
+	}
+	for key, want := range map[string]string{"title": "", "intro": "stale", "gone#title": "obsolete", "slices#title": "untranslated"} {
+		if kinds[key] != want {
+			t.Errorf("%s is %q, want %q", key, kinds[key], want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer func(o tourOptions) { options = o }(options)
+	options.lang = "es"
+	l, _ := findLesson("slices")
+	if got, want := lessonTitle(l), "Slices"; got != want {
+		t.Errorf("got title %q, want %q", got, want)
+	}
+	if got, want := tr("variables#title", "Variables & constants"), "Variables y constantes"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// a translation of English that changed since is not shown
+	if got, want := tr("variables#title", "Variables and constants"), "Variables and constants"; got != want {
+		t.Errorf("got stale translation %q, want %q", got, want)
+	}
+	doc, err := buildTourDoc([]Lesson{l}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Go en pocas palabras" || doc.Lessons[0].Steps[4].Heading != "copy - con un sub-rango" {
+		t.Errorf("tour not translated: %q, %q", doc.Title, doc.Lessons[0].Steps[4].Heading)
+	}
+	if err := checkLang("xx"); err == nil {
+		t.Error("no error for a language without a catalog")
+	}
+}
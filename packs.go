@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// A lesson pack adds lessons to the tour without changing gonutshell. It is
+// a directory with a pack.json manifest such as
+//
+//	{
+//		"name": "acme",
+//		"lessons": [{
+//			"id": "conventions",
+//			"title": "ACME coding conventions",
+//			"difficulty": "beginner",
+//			"tags": ["style"],
+//			"requires": ["functions"],
+//			"minutes": 10,
+//			"steps": [
+//				{"heading": "Naming", "snippet": "naming.go", "notes": "naming.md"}
+//			]
+//		}]
+//	}
+//
+// Every step is a Go program, a main package in a single file, along with
+// optional Markdown notes. The snippets are compiled with the local go
+// toolchain when the pack is loaded; a lesson with a snippet that does not
+// compile is left out.
+
+// packManifestFile is the name of the manifest of a pack.
+const packManifestFile = "pack.json"
+
+// packsEnv lists the directories packs are loaded from, as the -packs flag.
+const packsEnv = "GONUTSHELL_PACKS"
+
+// packManifest is the content of a pack.json file.
+type packManifest struct {
+	Name    string               `json:"name"`
+	Lessons []packLessonManifest `json:"lessons"`
+}
+
+type packLessonManifest struct {
+	ID         string             `json:"id"`
+	Title      string             `json:"title"`
+	Difficulty difficulty         `json:"difficulty"`
+	Tags       []string           `json:"tags"`
+	Requires   []string           `json:"requires"`
+	Minutes    int                `json:"minutes"`
+	Steps      []packStepManifest `json:"steps"`
+}
+
+type packStepManifest struct {
+	Heading string `json:"heading"`
+	Snippet string `json:"snippet"`         // Go file, relative to the pack
+	Notes   string `json:"notes,omitempty"` // Markdown file, relative to the pack
+}
+
+// packNamePattern is what the names of packs and the IDs of their lessons
+// look like, as they end up on command lines and in file names.
+var packNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// pack is a lesson pack as it was loaded.
+type pack struct {
+	Dir      string
+	Name     string
+	Lessons  []Lesson
+	Problems []string // lessons left out and why
+	leftOut  []string // the IDs of the lessons of the manifest left out
+}
+
+// packLesson is the part of a Lesson that comes from a pack: its steps
+// and the compiled snippet of each.
+type packLesson struct {
+	pack  string
+	steps []step
+	progs []string
+}
+
+// packs are the packs loaded by runCommand, for 'gonutshell packs'.
+var packs []*pack
+
+// packDirs returns the directories to look for packs in: those of the
+// -packs flag, or the packs directory in the user's config dir.
+func packDirs() []string {
+	if options.packs != "" {
+		return filepath.SplitList(options.packs)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(dir, "gonutshell", "packs")}
+}
+
+// findPacks returns the packs in dirs: a directory is a pack when it has a
+// manifest, else the packs are its sub-directories that have one.
+func findPacks(dirs []string) []string {
+	var found []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, packManifestFile)); err == nil {
+			found = append(found, dir)
+			continue
+		}
+		ms, _ := filepath.Glob(filepath.Join(dir, "*", packManifestFile))
+		for _, m := range ms {
+			found = append(found, filepath.Dir(m))
+		}
+	}
+	return found
+}
+
+// loadPack reads the pack in dir and compiles its snippets.
+func loadPack(ctx context.Context, dir string) (*pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, packManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m packManifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s: %v", packManifestFile, err)
+	}
+	if !packNamePattern.MatchString(m.Name) {
+		return nil, fmt.Errorf("%s: pack name %q is not lower case letters, digits and '-'", packManifestFile, m.Name)
+	}
+	p := &pack{Dir: dir, Name: m.Name}
+	for _, lm := range m.Lessons {
+		l, err := loadPackLesson(ctx, dir, m.Name, lm)
+		if err != nil {
+			p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: %v", lm.ID, err))
+			p.leftOut = append(p.leftOut, lm.ID)
+			continue
+		}
+		p.Lessons = append(p.Lessons, l)
+	}
+	return p, nil
+}
+
+func loadPackLesson(ctx context.Context, dir, name string, lm packLessonManifest) (Lesson, error) {
+	switch {
+	case !packNamePattern.MatchString(lm.ID):
+		return Lesson{}, errors.New("the ID is not lower case letters, digits and '-'")
+	case lm.Title == "":
+		return Lesson{}, errors.New("no title")
+	case len(lm.Steps) == 0:
+		return Lesson{}, errors.New("no steps")
+	}
+	// the files of a pack are in its directory, not anywhere ".." leads to
+	for _, sm := range lm.Steps {
+		if !filepath.IsLocal(sm.Snippet) {
+			return Lesson{}, fmt.Errorf("snippet %q is not a path within the pack", sm.Snippet)
+		}
+		if sm.Notes != "" && !filepath.IsLocal(sm.Notes) {
+			return Lesson{}, fmt.Errorf("notes %q is not a path within the pack", sm.Notes)
+		}
+	}
+	pl := &packLesson{pack: name}
+	for i, sm := range lm.Steps {
+		src, err := os.ReadFile(filepath.Join(dir, sm.Snippet))
+		if err != nil {
+			return Lesson{}, err
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), sm.Snippet, src, parser.PackageClauseOnly); err != nil || f.Name.Name != "main" {
+			return Lesson{}, fmt.Errorf("%s is not a main package", sm.Snippet)
+		}
+		prog, err := buildSnippet(ctx, string(src))
+		if be, ok := err.(*buildError); ok {
+			diags := snippetDiagnostics(be.Output, "main.go", 1, strings.Count(string(src), "\n")+1)
+			return Lesson{}, fmt.Errorf("%s does not compile:\n%s", sm.Snippet, strings.TrimRight(diags, "\n"))
+		}
+		if err != nil {
+			return Lesson{}, err
+		}
+		// a step is a program of its own, attributed a line of its own
+		s := step{Heading: sm.Heading, Level: 2, Start: i + 1, End: i + 1, Code: strings.TrimSpace(string(src))}
+		if sm.Notes != "" {
+			md, err := os.ReadFile(filepath.Join(dir, sm.Notes))
+			if err != nil {
+				return Lesson{}, err
+			}
+			s.Notes = []string{strings.TrimSpace(string(md))}
+		}
+		pl.steps = append(pl.steps, s)
+		pl.progs = append(pl.progs, prog)
+	}
+	l := Lesson{
+		ID: lm.ID, Title: lm.Title,
+		Difficulty: lm.Difficulty, Tags: lm.Tags, Requires: lm.Requires, Minutes: lm.Minutes,
+		pack: pl,
+	}
+	l.Run = func(w io.Writer) { pl.run(w, func(int) {}) }
+	return l, nil
+}
+
+// run runs the snippets of the lesson one after the other, writing their
+// output to w, and calls next before each with the index of its step.
+func (pl *packLesson) run(w io.Writer, next func(i int)) {
+	for i, prog := range pl.progs {
+		next(i)
+		if err := runProgram(context.Background(), prog, defaultLimits, w); err != nil {
+			fmt.Fprintf(w, "[%v]\n", err)
+		}
+	}
+}
+
+// capture runs the lesson as captureLesson does. A step of a pack lesson
+// is the one line its output is attributed to, see loadPackLesson.
+func (pl *packLesson) capture() transcript {
+	sw := &stepWriter{}
+	pl.run(lessonWriter(sw), func(i int) { sw.line = i + 1 })
+	return sw.chunks
+}
+
+// stepWriter records writes with the line set by its user.
+type stepWriter struct {
+	line   int
+	chunks transcript
+}
+
+func (sw *stepWriter) Write(p []byte) (int, error) {
+	sw.chunks = append(sw.chunks, chunk{line: sw.line, text: string(p)})
+	return len(p), nil
+}
+
+// packCacheDir returns where compiled snippets are kept between runs.
+var packCacheDir = func() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gonutshell", "packs"), nil
+}
+
+// goVersion returns the version of the go toolchain building the snippets,
+// which need not be the one gonutshell was built with.
+var goVersion = sync.OnceValues(func() (string, error) {
+	cmd := exec.Command("go", "env", "GOVERSION")
+	cmd.Env = sandboxEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env GOVERSION: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+})
+
+// buildSnippet compiles the program src, unless it was compiled with this
+// toolchain before, and returns the path of the executable.
+func buildSnippet(ctx context.Context, src string) (string, error) {
+	dir, err := packCacheDir()
+	if err != nil {
+		return "", err
+	}
+	version, err := goVersion()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(version + "\x00" + src))
+	prog := filepath.Join(dir, hex.EncodeToString(sum[:12]))
+	if _, err := os.Stat(prog); err == nil {
+		return prog, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// build next to the cached one and rename, for gonutshell running twice
+	tmp := fmt.Sprintf("%s.%d", prog, os.Getpid())
+	ctx, cancel := context.WithTimeout(ctx, defaultLimits.Timeout)
+	defer cancel()
+	if err := buildSandboxed(ctx, map[string]string{"main.go": src}, tmp); err != nil {
+		return "", err
+	}
+	return prog, os.Rename(tmp, prog)
+}
+
+// mergePacks adds the lessons of ps to base, each after those it requires.
+// A lesson with the ID of one already there is renamed <pack>-<id>, and
+// the lessons of its pack that require it follow; lessons requiring ones
+// that do not exist, or that their pack left out, are left out, and so are
+// the lessons of a pack whose prerequisites form a cycle.
+func mergePacks(base []Lesson, ps []*pack) []Lesson {
+	merged := slices.Clone(base)
+	for _, p := range ps {
+		ids := map[string]string{} // in the pack to in the registry
+		leftOut := map[string]bool{}
+		for _, id := range p.leftOut {
+			leftOut[id] = true
+		}
+		var added []Lesson
+		for _, l := range p.Lessons {
+			if _, dup := ids[l.ID]; dup {
+				p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: the pack has a lesson %s already", l.ID, l.ID))
+				continue
+			}
+			id := l.ID
+			if _, taken := findIn(slices.Concat(merged, added), id); taken {
+				id = p.Name + "-" + l.ID
+				if _, taken := findIn(slices.Concat(merged, added), id); taken {
+					p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: there are lessons %s and %s already", l.ID, l.ID, id))
+					leftOut[l.ID] = true
+					continue
+				}
+				p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: renamed %s, there is a lesson %s already", l.ID, id, l.ID))
+			}
+			ids[l.ID] = id
+			l.ID = id
+			added = append(added, l)
+		}
+		// requirements name lessons of the pack first, then of the tour,
+		// but not a lesson of the tour with the ID of one left out
+		resolved := added[:0]
+		for _, l := range added {
+			reqs, met := make([]string, len(l.Requires)), true
+			for j, r := range l.Requires {
+				if _, ok := ids[r]; !ok && leftOut[r] {
+					p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: requires %q, which the pack left out", l.ID, r))
+					met = false
+					break
+				}
+				reqs[j] = cmp.Or(ids[r], r)
+			}
+			if met {
+				l.Requires = reqs
+				resolved = append(resolved, l)
+			}
+		}
+		added = resolved
+		for left := true; left; {
+			left = false
+			for i, l := range added {
+				for _, r := range l.Requires {
+					if _, ok := findIn(slices.Concat(merged, added), r); !ok {
+						p.Problems = append(p.Problems, fmt.Sprintf("lesson %q: requires %q, which is not a lesson", l.ID, r))
+						added, left = slices.Delete(added, i, i+1), true
+						break
+					}
+				}
+				if left {
+					break
+				}
+			}
+		}
+		order, err := curriculum(slices.Concat(merged, added))
+		if err != nil {
+			p.Problems = append(p.Problems, fmt.Sprintf("%v, leaving out the lessons of the pack", err))
+			continue
+		}
+		merged = order
+	}
+	return merged
+}
+
+// loadPacks loads the packs found in dirs and adds their lessons to the
+// registry, reporting the lessons it could not add to w.
+func loadPacks(w io.Writer, dirs []string) {
+	packs = nil
+	for _, dir := range findPacks(dirs) {
+		p, err := loadPack(context.Background(), dir)
+		if err != nil {
+			fmt.Fprintf(w, "gonutshell: pack %s: %v\n", dir, err)
+			continue
+		}
+		packs = append(packs, p)
+	}
+	lessons = mergePacks(tourLessons(lessons), packs)
+	for _, p := range packs {
+		for _, prob := range p.Problems {
+			fmt.Fprintf(w, "gonutshell: pack %s: %s\n", p.Name, prob)
+		}
+	}
+}
+
+// tourLessons returns the lessons of ls that are part of gonutshell itself,
+// as opposed to those of packs.
+func tourLessons(ls []Lesson) []Lesson {
+	return slices.DeleteFunc(slices.Clone(ls), func(l Lesson) bool { return l.pack != nil })
+}
+
+func cmdPacks(w io.Writer, args []string) error {
+	if len(packs) == 0 {
+		fmt.Fprintf(w, "No lesson packs in %s.\n", strings.Join(packDirs(), string(filepath.ListSeparator)))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, p := range packs {
+		fmt.Fprintf(tw, "%s\t%s\n", p.Name, p.Dir)
+		for _, l := range lessons {
+			if l.pack != nil && l.pack.pack == p.Name {
+				n := fmt.Sprintf("%d steps", len(l.pack.steps))
+				if len(l.pack.steps) == 1 {
+					n = "1 step"
+				}
+				fmt.Fprintf(tw, "  %s\t%s, %s\n", l.ID, l.Title, n)
+			}
+		}
+		for _, prob := range p.Problems {
+			fmt.Fprintf(tw, "  !\t%s\n", strings.ReplaceAll(prob, "\n", "\n  \t"))
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePack writes the files of a pack, by name, to a new directory.
+func writePack(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMergePacks(t *testing.T) {
+	p := &pack{Name: "acme", Lessons: []Lesson{
+		{ID: "conventions", Requires: []string{"functions"}},
+		{ID: "slices", Requires: []string{"conventions"}},
+		{ID: "pools", Requires: []string{"slices"}},
+		{ID: "orphan", Requires: []string{"nope"}},
+		{ID: "lonelier", Requires: []string{"orphan"}},
+		{ID: "pools"},
+		{ID: "acme-slices"},
+	}}
+	merged := mergePacks(lessons, []*pack{p})
+	if got, want := lessonIDs(merged[len(lessons):]), "conventions acme-slices pools acme-acme-slices"; got != want {
+		t.Errorf("added lessons %q, want %q", got, want)
+	}
+	if l, _ := findIn(merged, "pools"); strings.Join(l.Requires, " ") != "acme-slices" {
+		t.Errorf("pools requires %q, not the renamed acme-slices", l.Requires)
+	}
+	if l, _ := findIn(merged, "slices"); l.pack != nil {
+		t.Error("the slices lesson of the tour was replaced")
+	}
+	if _, err := curriculum(merged); err != nil {
+		t.Error(err)
+	}
+	want := []string{
+		`lesson "slices": renamed acme-slices, there is a lesson slices already`,
+		`lesson "pools": the pack has a lesson pools already`,
+		`lesson "acme-slices": renamed acme-acme-slices, there is a lesson acme-slices already`,
+		`lesson "orphan": requires "nope", which is not a lesson`,
+		`lesson "lonelier": requires "orphan", which is not a lesson`,
+	}
+	if got := strings.Join(p.Problems, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("problems are\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestMergePacksOrder(t *testing.T) {
+	p := &pack{Name: "acme", Lessons: []Lesson{
+		{ID: "advanced", Requires: []string{"basics"}},
+		{ID: "basics", Requires: []string{"functions"}},
+		{ID: "hashing", Requires: []string{"maps"}}, // the one of the pack, which did not compile
+	}, leftOut: []string{"maps"}}
+	loop := &pack{Name: "loop", Lessons: []Lesson{
+		{ID: "chicken", Requires: []string{"egg"}},
+		{ID: "egg", Requires: []string{"chicken"}},
+	}}
+	merged := mergePacks(lessons, []*pack{p, loop})
+	if got, want := lessonIDs(merged), lessonIDs(lessons)+" basics advanced"; got != want {
+		t.Errorf("merged lessons %q, want %q", got, want)
+	}
+	if got, want := strings.Join(p.Problems, "\n"), `lesson "hashing": requires "maps", which the pack left out`; got != want {
+		t.Errorf("problems of acme are %q, want %q", got, want)
+	}
+	if got, want := strings.Join(loop.Problems, "\n"), "the prerequisites of the lessons form a cycle: chicken -> egg -> chicken, leaving out the lessons of the pack"; got != want {
+		t.Errorf("problems of loop are %q, want %q", got, want)
+	}
+}
+
+// TestPacksOnlyForLessons checks that commands that do not use the
+// lessons do not load the packs.
+func TestPacksOnlyForLessons(t *testing.T) {
+	defer func(o tourOptions, ls []Lesson) { options, lessons = o, ls }(options, lessons)
+	dir := writePack(t, map[string]string{"pack.json": `{"name": "Acme"}`})
+	for _, tt := range []struct {
+		args  []string
+		loads bool
+	}{
+		{[]string{"help"}, false},
+		{[]string{"list"}, true},
+	} {
+		var stderr strings.Builder
+		runCommand(io.Discard, &stderr, append([]string{"-packs", dir}, tt.args...))
+		if loaded := strings.Contains(stderr.String(), `pack name "Acme"`); loaded != tt.loads {
+			t.Errorf("gonutshell %s: packs loaded %v, want %v:\n%s", tt.args[0], loaded, tt.loads, stderr.String())
+		}
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the snippets of a pack")
+	}
+	cache := t.TempDir()
+	defer func(old func() (string, error)) { packCacheDir = old }(packCacheDir)
+	packCacheDir = func() (string, error) { return cache, nil }
+
+	dir := writePack(t, map[string]string{
+		"pack.json": `{
+			"name": "acme",
+			"lessons": [
+				{"id": "greetings", "title": "Greetings", "difficulty": "intermediate", "requires": ["functions"],
+				 "steps": [{"heading": "Hello", "snippet": "hello.go", "notes": "hello.md"}, {"heading": "Bye", "snippet": "bye.go"}]},
+				{"id": "broken", "title": "Broken", "steps": [{"heading": "Unused", "snippet": "broken.go"}]}
+			]
+		}`,
+		"hello.go":  "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n",
+		"hello.md":  "Say *hello*.\n",
+		"bye.go":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"bye\")\n}\n",
+		"broken.go": "package main\n\nfunc main() {\n\tx := 1\n}\n",
+	})
+	p, err := loadPack(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lessonIDs(p.Lessons), "greetings"; got != want {
+		t.Fatalf("loaded lessons %q, want %q", got, want)
+	}
+	want := "lesson \"broken\": broken.go does not compile:\nline 4:2: declared and not used: x"
+	if len(p.Problems) != 1 || p.Problems[0] != want {
+		t.Errorf("problems are %q, want %q", p.Problems, want)
+	}
+
+	l := p.Lessons[0]
+	if l.Difficulty != intermediate {
+		t.Errorf("difficulty is %v, want intermediate", l.Difficulty)
+	}
+	steps, err := lessonSteps(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := captureLesson(l)
+	for i, want := range []string{"hello\n", "bye\n"} {
+		if got := steps[i].output(out); got != want {
+			t.Errorf("step %d printed %q, want %q", i+1, got, want)
+		}
+	}
+	if got := strings.Join(steps[0].Notes, ""); got != "Say *hello*." {
+		t.Errorf("notes of step 1 are %q", got)
+	}
+
+	code := strings.Replace(steps[1].Code, `"bye"`, `"see you"`, 1)
+	var b strings.Builder
+	if err := runEditedStep(t.Context(), l, steps[1], code, defaultLimits, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "see you\n" {
+		t.Errorf("edited step printed %q", b.String())
+	}
+
+	// a second load finds the programs compiled already
+	entries, _ := os.ReadDir(cache)
+	if _, err := loadPack(t.Context(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadDir(cache); len(again) != len(entries) {
+		t.Errorf("the cache went from %d to %d programs", len(entries), len(again))
+	}
+}
+
+func TestLoadPackManifest(t *testing.T) {
+	for manifest, want := range map[string]string{
+		`{"name": "Acme"}`: `pack name "Acme"`,
+		`{"name": "acme", "lessons": [{"id": "x", "difficulty": "expert"}]}`: `unknown difficulty "expert"`,
+		`{"name": "acme", "lesons": []}`:                                     `unknown field "lesons"`,
+	} {
+		_, err := loadPack(t.Context(), writePack(t, map[string]string{"pack.json": manifest}))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("loading %s: error %v, want %q", manifest, err, want)
+		}
+	}
+}
+
+func TestLoadPackPaths(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"pack.json": `{
+			"name": "acme",
+			"lessons": [
+				{"id": "up", "title": "Up", "steps": [{"heading": "Out", "snippet": "../main.go"}]},
+				{"id": "root", "title": "Root", "steps": [{"heading": "Out", "snippet": "/etc/main.go"}]},
+				{"id": "notes", "title": "Notes", "steps": [{"heading": "Out", "snippet": "main.go", "notes": "../../notes.md"}]}
+			]
+		}`,
+	})
+	p, err := loadPack(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`lesson "up": snippet "../main.go" is not a path within the pack`,
+		`lesson "root": snippet "/etc/main.go" is not a path within the pack`,
+		`lesson "notes": notes "../../notes.md" is not a path within the pack`,
+	}
+	if got := strings.Join(p.Problems, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("problems are\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// stepBegin and stepEnd are written around the code of the step that a
+// lesson program was built for, so that its output can be told apart from
+// the output of the rest of the lesson.
+const (
+	stepBegin = "\x02"
+	stepEnd   = "\x03"
+)
+
+// programPrelude stands in for the parts of gonutshell the lessons use
+// that do not come with the tour files.
+const programPrelude = `package main
+
+// options are always off when a lesson runs on its own.
+var options struct{ deterministic, sortedMaps bool }
+`
+
+// lessonProgram returns the files of a program that runs lesson l with
+// the code of step s replaced by code. It is made of the tour files with
+// main calling the lesson function, so the step keeps the variables and
+// helpers it relies on. The code starts on line codeLine of file.
+func lessonProgram(l Lesson, s step, code string) (files map[string]string, file string, codeLine int, err error) {
+	t, err := loadTour()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	fd, lf, err := t.lessonDecl(l)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	file = t.fset.Position(lf.Pos()).Filename
+	edits := t.mainEdits(fd.Name.Name + "(os.Stdout)")
+	edits[file] = append(edits[file], lineEdit{s.Start + 1, s.End, slices.Concat(
+		[]string{fmt.Sprintf("fmt.Fprint(w, %q)", stepBegin)},
+		strings.Split(code, "\n"),
+		[]string{fmt.Sprintf("fmt.Fprint(w, %q)", stepEnd)},
+	)})
+
+	// the code follows the stepBegin line, once the edits above it are done
+	codeLine = s.Start + 2
+	for _, e := range edits[file] {
+		if e.from < s.Start {
+			codeLine += len(e.lines) - (e.to - e.from + 1)
+		}
+	}
+	files, err = tourProgram(edits)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return files, file, codeLine, nil
+}
+
+// lineEdit is a replacement of lines from..to of a file.
+type lineEdit struct {
+	from, to int
+	lines    []string
+}
+
+// mainEdits returns the edit, by file, that replaces the body of main with
+// body.
+func (t *tourSource) mainEdits(body ...string) map[string][]lineEdit {
+	edits := map[string][]lineEdit{}
+	for _, f := range t.files {
+		for _, d := range f.Decls {
+			if m, ok := d.(*ast.FuncDecl); ok && m.Recv == nil && m.Name.Name == "main" {
+				name := t.fset.Position(f.Pos()).Filename
+				edits[name] = append(edits[name], lineEdit{t.line(m.Body.Lbrace) + 1, t.line(m.Body.Rbrace) - 1, body})
+			}
+		}
+	}
+	return edits
+}
+
+// tourProgram returns the files of a program made of the tour files with
+// edits, by file, done and programPrelude.
+func tourProgram(edits map[string][]lineEdit) (map[string]string, error) {
+	files := map[string]string{"prelude.go": programPrelude}
+	for _, name := range tourFiles {
+		src, err := tourFS.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(string(src), "\n")
+		es := edits[name]
+		slices.SortFunc(es, func(a, b lineEdit) int { return b.from - a.from })
+		for _, e := range es {
+			lines = slices.Replace(lines, e.from-1, e.to, e.lines...)
+		}
+		files[name] = strings.Join(lines, "\n")
+	}
+	return files, nil
+}
+
+// buildPosPattern matches the positions in the output of go build.
+var buildPosPattern = regexp.MustCompile(`(?m)^(?:\./)?([\w.-]+\.go):(\d+):(\d+): `)
+
+// snippetDiagnostics rewrites the positions go build reports for the n
+// lines of edited code that start on line codeLine of file to be relative
+// to the code, and drops the line naming the package.
+func snippetDiagnostics(out, file string, codeLine, n int) string {
+	out = strings.TrimPrefix(out, "# playground\n")
+	return buildPosPattern.ReplaceAllStringFunc(out, func(pos string) string {
+		m := buildPosPattern.FindStringSubmatch(pos)
+		line, _ := strconv.Atoi(m[2])
+		if m[1] != file || line < codeLine || line >= codeLine+n {
+			return pos
+		}
+		return fmt.Sprintf("line %d:%s: ", line-codeLine+1, m[3])
+	})
+}
+
+// stepFilter passes on only the output written between stepBegin and
+// stepEnd.
+type stepFilter struct {
+	w  io.Writer
+	in bool
+}
+
+func (f *stepFilter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if !f.in {
+			i := bytes.IndexByte(p, stepBegin[0])
+			if i < 0 {
+				break
+			}
+			p, f.in = p[i+1:], true
+			continue
+		}
+		i := bytes.IndexByte(p, stepEnd[0])
+		if i < 0 {
+			_, err := f.w.Write(p)
+			return n, err
+		}
+		if _, err := f.w.Write(p[:i]); err != nil {
+			return n, err
+		}
+		p, f.in = p[i+1:], false
+	}
+	return n, nil
+}
+
+// runEditedStep runs lesson l with the code of step s replaced by code in
+// the sandbox, writing the output of the step to w. Compile errors in the
+// code are reported with lines counted from its first line.
+func runEditedStep(ctx context.Context, l Lesson, s step, code string, lim sandboxLimits, w io.Writer) error {
+	if l.pack != nil {
+		// the step is a whole program
+		err := runSandboxed(ctx, map[string]string{"main.go": code}, lim, w)
+		if be, ok := err.(*buildError); ok {
+			be.Output = snippetDiagnostics(be.Output, "main.go", 1, strings.Count(code, "\n")+1)
+		}
+		return err
+	}
+	files, file, codeLine, err := lessonProgram(l, s, code)
+	if err != nil {
+		return err
+	}
+	err = runSandboxed(ctx, files, lim, &stepFilter{w: w})
+	if be, ok := err.(*buildError); ok {
+		be.Output = snippetDiagnostics(be.Output, file, codeLine, strings.Count(code, "\n")+1)
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// progressEnv names the progress file instead of the one in the user's
+// config dir.
+const progressEnv = "GONUTSHELL_PROGRESS"
+
+// progress is what a learner has done so far, kept in a JSON file between
+// sessions since onboarding spans several days.
+type progress struct {
+	Lessons map[string]*lessonProgress `json:"lessons"`
+	// Last is the lesson step mode was last left in.
+	Last    string    `json:"last,omitempty"`
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+}
+
+// lessonProgress is the progress on one lesson.
+type lessonProgress struct {
+	Step      int         `json:"step"` // last step shown in step mode
+	Steps     int         `json:"steps"`
+	Completed time.Time   `json:"completed,omitzero"`
+	Quiz      *quizRecord `json:"quiz,omitempty"`
+	Visited   time.Time   `json:"visited"`
+}
+
+// quizRecord is the last and the best score of the quiz on a lesson.
+type quizRecord struct {
+	Correct int       `json:"correct"`
+	Asked   int       `json:"asked"`
+	Best    int       `json:"best"`
+	Taken   time.Time `json:"taken"`
+}
+
+// now is the clock of the progress file.
+var now = time.Now
+
+// progressPath returns where the progress file is kept.
+func progressPath() (string, error) {
+	if p := os.Getenv(progressEnv); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gonutshell", "progress.json"), nil
+}
+
+// loadProgress reads the progress file, which does not exist before the
+// first session.
+func loadProgress() (*progress, error) {
+	p := &progress{Lessons: map[string]*lessonProgress{}, Started: now()}
+	path, err := progressPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if p.Lessons == nil {
+		p.Lessons = map[string]*lessonProgress{}
+	}
+	return p, nil
+}
+
+// save writes p back to the progress file.
+func (p *progress) save() error {
+	path, err := progressPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	p.Updated = now()
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// lesson returns the progress on the lesson id, adding it when needed.
+func (p *progress) lesson(id string) *lessonProgress {
+	lp, ok := p.Lessons[id]
+	if !ok {
+		lp = &lessonProgress{}
+		p.Lessons[id] = lp
+	}
+	lp.Visited = now()
+	return lp
+}
+
+// stepped records that step mode on the lesson id was left at step i of n,
+// which completes the lesson when it was gone through to the end.
+func (p *progress) stepped(id string, i, n int, done bool) {
+	lp := p.lesson(id)
+	lp.Step, lp.Steps = i, n
+	if done && lp.Completed.IsZero() {
+		lp.Completed = now()
+	}
+	p.Last = id
+}
+
+// quizzed records the scores of a quiz.
+func (p *progress) quizzed(scores []quizScore) {
+	for _, s := range scores {
+		lp := p.lesson(s.Lesson)
+		best := s.Correct
+		if lp.Quiz != nil {
+			best = max(best, lp.Quiz.Best)
+		}
+		lp.Quiz = &quizRecord{Correct: s.Correct, Asked: s.Asked, Best: best, Taken: now()}
+	}
+}
+
+// stepLesson runs step mode on l from step start and records the progress.
+func stepLesson(w io.Writer, l Lesson, start int) error {
+	steps, err := lessonSteps(l)
+	if err != nil {
+		return err
+	}
+	p, err := loadProgress()
+	if err != nil {
+		return err
+	}
+	if start == 0 {
+		warnJumpAhead(w, p, l)
+	}
+	last, done, err := stepThrough(w, bufio.NewScanner(stdin), l, steps, start)
+	if err != nil {
+		return err
+	}
+	p.stepped(l.ID, last, len(steps), done)
+	return p.save()
+}
+
+func cmdProgress(w io.Writer, args []string) error {
+	p, err := loadProgress()
+	if err != nil {
+		return err
+	}
+	if len(p.Lessons) == 0 {
+		fmt.Fprintln(w, "No progress yet, start with 'gonutshell step variables'.")
+		return nil
+	}
+	const day = "2006-01-02 15:04"
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "lesson\tsteps\tquiz\tlast visited")
+	done := 0
+	for _, l := range lessons {
+		lp, ok := p.Lessons[l.ID]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\n", l.ID)
+			continue
+		}
+		status := "-"
+		switch {
+		case !lp.Completed.IsZero():
+			done++
+			status = "done"
+		case lp.Steps > 0:
+			status = fmt.Sprintf("%d/%d", lp.Step+1, lp.Steps)
+		}
+		quiz := "-"
+		if lp.Quiz != nil {
+			quiz = fmt.Sprintf("%d/%d (best %d)", lp.Quiz.Correct, lp.Quiz.Asked, lp.Quiz.Best)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", l.ID, status, quiz, lp.Visited.Format(day))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d of %d lessons completed, started %s.\n", done, len(lessons), p.Started.Format(day))
+	if next, _, ok := p.resumePoint(); ok {
+		fmt.Fprintf(w, "'gonutshell resume' continues with %s.\n", next.ID)
+	}
+	return nil
+}
+
+// resumePoint returns where to continue: the lesson step mode was left in,
+// unless it was completed, in which case the first lesson after it that
+// was not.
+func (p *progress) resumePoint() (Lesson, int, bool) {
+	from := 0
+	for i, l := range lessons {
+		if l.ID != p.Last {
+			continue
+		}
+		if lp := p.Lessons[l.ID]; lp != nil && lp.Completed.IsZero() {
+			return l, lp.Step, true
+		}
+		from = i + 1
+	}
+	for i := range lessons {
+		l := lessons[(from+i)%len(lessons)]
+		if lp, ok := p.Lessons[l.ID]; !ok || lp.Completed.IsZero() {
+			return l, 0, true
+		}
+	}
+	return Lesson{}, 0, false
+}
+
+func cmdResume(w io.Writer, args []string) error {
+	p, err := loadProgress()
+	if err != nil {
+		return err
+	}
+	l, start, ok := p.resumePoint()
+	if !ok {
+		fmt.Fprintln(w, "All lessons completed, well done!")
+		return nil
+	}
+	fmt.Fprintf(w, "Resuming %s at step %d.\n", l.ID, start+1)
+	return stepLesson(w, l, start)
+}
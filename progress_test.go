@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	t.Setenv(progressEnv, filepath.Join(t.TempDir(), "progress.json"))
+	defer func(r func() time.Time) { now = r }(now)
+	now = func() time.Time { return time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC) }
+	defer func(r io.Reader) { stdin = r }(stdin)
+
+	// leave slices at its third step, then resume and finish it
+	stdin = strings.NewReader("\n\nq\n")
+	var out strings.Builder
+	if err := cmdStep(&out, []string{"slices"}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, start, _ := p.resumePoint(); l.ID != "slices" || start != 2 {
+		t.Fatalf("resume point = %s step %d, want slices step 2", l.ID, start)
+	}
+	stdin = strings.NewReader(strings.Repeat("\n", 10))
+	out.Reset()
+	if err := cmdResume(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Resuming slices at step 3.") {
+		t.Errorf("resume printed %q", out.String()[:40])
+	}
+
+	stdin = strings.NewReader("Just right\nEqual 20\nAt 10\n\n")
+	if err := cmdQuiz(&out, []string{"control-flow"}); err != nil {
+		t.Fatal(err)
+	}
+	p, err = loadProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp := p.Lessons["slices"]; lp == nil || lp.Completed.IsZero() {
+		t.Errorf("slices not completed: %+v", lp)
+	}
+	if q := p.Lessons["control-flow"].Quiz; q == nil || q.Correct != 1 || q.Asked != 1 {
+		t.Errorf("control-flow quiz = %+v, want 1/1", q)
+	}
+	if l, start, _ := p.resumePoint(); l.ID != "slice-internals" || start != 0 {
+		t.Errorf("resume point = %s step %d, want slice-internals step 0", l.ID, start)
+	}
+
+	out.Reset()
+	if err := cmdProgress(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	completed := fmt.Sprintf("1 of %d lessons completed", len(lessons))
+	for _, want := range []string{"done", "1/1 (best 1)", completed, "continues with slice-internals"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("progress does not show %q:\n%s", want, out.String())
+		}
+	}
+}
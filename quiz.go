@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// question asks the learner to predict the output of a step of a lesson.
+type question struct {
+	Lesson string
+	Step   string // heading of the step, see findStep
+	Ask    string
+}
+
+// quiz is the list of questions, in the order of the tour.
+var quiz = []question{
+	{"arrays", "Standard built-in collections", "What does this print? Mind the value of b5."},
+	{"slices", "copy - with sub-range", "What are the three values of o2 that are printed?"},
+	{"maps", "delete from a map - delete()", "What does printing scores show once Bob is deleted?"},
+	{"control-flow", "switch / case", "sw1 is 20. What do the three switch statements print?"},
+	{"control-flow", "'break' and 'continue'", "What does this loop print?"},
+	{"control-flow", "multiple variables", "When does this loop stop, and what does it print?"},
+	{"variadic", "Gotcha - Note that the 'slice' can get modified", "What do the two Println calls print?"},
+	{"first-class", "function literals & closures", "What does each call of switchAction print?"},
+	{"first-class", "HOF - Returning functions from HOF / function factory", "What do the first calls of c1 and c2 return?"},
+	{"strings", "for-range loop on strings", `str1 is "Señor". What does ranging over it print?`},
+	{"structs", "structs are value types", "What do the three Println calls print?"},
+	{"structs", "copies share what their fields point to", "What does printing t1 show?"},
+	{"structs", "method sets", "What are p4 and scale(2) at the end?"},
+	{"structs", "shadowing", "Which Scaled method does each call use, and what do they print?"},
+	{"interfaces", "type assertions - comma ok", "What do the three assertions give?"},
+	{"interfaces", "nil interface vs nil pointer", "Is p nil? Is m nil?"},
+	{"interfaces", "fmt.Formatter", "How does money print with each of the four verbs?"},
+	{"errors", "wrapping - fmt.Errorf & errors.Is", "What does printing err show, and what do the two comparisons give?"},
+	{"errors", "defer ordering", "In what order do the deferred calls of countdown print?"},
+	{"errors", "panic & recover", "What error does recoveredDelete return?"},
+	{"concurrency", "buffered channels", "What do len and cap of the queue give, and then the two receives?"},
+	{"concurrency", "range over a channel & close", "What does the receive after the range loop give?"},
+	{"concurrency", "jobs & results", "What are the partial sums, chunk by chunk?"},
+	{"counters", "step & limit", "What do the four calls of each counter give?"},
+	{"counters", "Reset & Snapshot", "What are the two snapshots of wrap?"},
+}
+
+// quizScore is how the learner did on the questions of a lesson.
+type quizScore struct {
+	Lesson         string
+	Asked, Correct int
+}
+
+// annotationPattern matches '// Output:' comments, which would give the
+// answers away.
+var annotationPattern = regexp.MustCompile(`\s*// ` + outputMarker + `.*$`)
+
+// stripAnnotations removes the '// Output:' comments from code.
+func stripAnnotations(code string) string {
+	var out []string
+	for _, l := range strings.Split(code, "\n") {
+		s := annotationPattern.ReplaceAllString(l, "")
+		if s == "" && l != "" {
+			continue
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, "\n")
+}
+
+// runQuiz asks the questions qs, reading the predictions from in. It stops
+// early when in runs out and returns the scores per lesson so far.
+func runQuiz(w io.Writer, in *bufio.Scanner, qs []question) ([]quizScore, error) {
+	var scores []quizScore
+	for n, q := range qs {
+		l, ok := findLesson(q.Lesson)
+		if !ok {
+			return scores, fmt.Errorf("question about unknown lesson %q", q.Lesson)
+		}
+		steps, err := lessonSteps(l)
+		if err != nil {
+			return scores, err
+		}
+		i, ok := findStep(steps, q.Step)
+		if !ok {
+			return scores, fmt.Errorf("question about unknown step %q of %s", q.Step, q.Lesson)
+		}
+		s := steps[i]
+		fmt.Fprintf(w, "\nQuestion %d/%d (%s: %s)\n\n", n+1, len(qs), l.ID, s.Heading)
+		fmt.Fprintf(w, "%s\n\n%s\n", indent(stripAnnotations(s.Code), "    "), q.Ask)
+		fmt.Fprintln(w, "Type the output you expect, then an empty line:")
+		var guess []string
+		for {
+			fmt.Fprint(w, "> ")
+			if !in.Scan() {
+				fmt.Fprintln(w)
+				return scores, in.Err()
+			}
+			if in.Text() == "" {
+				break
+			}
+			guess = append(guess, in.Text())
+		}
+
+		if len(scores) == 0 || scores[len(scores)-1].Lesson != l.ID {
+			scores = append(scores, quizScore{Lesson: l.ID})
+		}
+		score := &scores[len(scores)-1]
+		score.Asked++
+		want := outputLines(s.output(captureLesson(l)))
+		got := outputLines(strings.Join(guess, "\n"))
+		if slices.Equal(want, got) {
+			score.Correct++
+			fmt.Fprintln(w, "\nCorrect!")
+		} else {
+			fmt.Fprintln(w, "\nNot quite (- is the real output, + your prediction):")
+			for _, dl := range diffLines(want, got) {
+				fmt.Fprintf(w, "  %c %s\n", dl.op, dl.text)
+			}
+		}
+		for _, note := range s.Notes {
+			fmt.Fprintf(w, "\n%s\n", indent(note, "  | "))
+		}
+	}
+	return scores, nil
+}
+
+// lessonQuestions returns the questions about the lessons in sel.
+func lessonQuestions(sel []Lesson) []question {
+	var qs []question
+	for _, l := range sel {
+		for _, q := range quiz {
+			if q.Lesson == l.ID {
+				qs = append(qs, q)
+			}
+		}
+	}
+	return qs
+}
+
+// printScores sums up the scores of a quiz.
+func printScores(w io.Writer, scores []quizScore) error {
+	asked, correct := 0, 0
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, s := range scores {
+		asked += s.Asked
+		correct += s.Correct
+		fmt.Fprintf(tw, "  %s\t%d/%d\n", s.Lesson, s.Correct, s.Asked)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nScore: %d/%d\n", correct, asked)
+	return err
+}
+
+func cmdQuiz(w io.Writer, args []string) error {
+	sel, err := selectLessons(args)
+	if err != nil {
+		return err
+	}
+	qs := lessonQuestions(sel)
+	if len(qs) == 0 {
+		return fmt.Errorf("no questions about %s yet", strings.Join(args, ", "))
+	}
+	scores, err := runQuiz(w, bufio.NewScanner(stdin), qs)
+	if err != nil {
+		return err
+	}
+	if err := printScores(w, scores); err != nil {
+		return err
+	}
+	p, err := loadProgress()
+	if err != nil {
+		return err
+	}
+	p.quizzed(scores)
+	return p.save()
+}
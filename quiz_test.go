@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestQuizQuestions(t *testing.T) {
+	for _, q := range quiz {
+		l, ok := findLesson(q.Lesson)
+		if !ok {
+			t.Errorf("%q: unknown lesson %q", q.Ask, q.Lesson)
+			continue
+		}
+		steps, err := lessonSteps(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, ok := findStep(steps, q.Step)
+		if !ok {
+			t.Errorf("%s: no step %q", q.Lesson, q.Step)
+			continue
+		}
+		if steps[i].output(captureLesson(l)) == "" {
+			t.Errorf("%s: step %q prints nothing to predict", q.Lesson, q.Step)
+		}
+		if strings.Contains(stripAnnotations(steps[i].Code), outputMarker) {
+			t.Errorf("%s: step %q gives the answer away", q.Lesson, q.Step)
+		}
+	}
+}
+
+func TestRunQuiz(t *testing.T) {
+	l, _ := findLesson("control-flow")
+	qs := lessonQuestions([]Lesson{l})
+	in := bufio.NewScanner(strings.NewReader("Just right\nEqual 20\nAt 20\n\n1  3 5 7 8 9 10 \n\n"))
+	var out strings.Builder
+	scores, err := runQuiz(&out, in, qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []quizScore{{Lesson: "control-flow", Asked: 2, Correct: 1}}
+	if len(scores) != 1 || scores[0] != want[0] {
+		t.Errorf("scores = %v, want %v", scores, want)
+	}
+	if !strings.Contains(out.String(), "  - At 10\n  + At 20\n") {
+		t.Errorf("wrong answer not diffed:\n%s", out.String())
+	}
+}
+
+func TestPrintScores(t *testing.T) {
+	var b strings.Builder
+	scores := []quizScore{{Lesson: "maps", Asked: 2, Correct: 2}, {Lesson: "control-flow", Asked: 3, Correct: 1}}
+	if err := printScores(&b, scores); err != nil {
+		t.Fatal(err)
+	}
+	want := "  maps          2/2\n  control-flow  1/3\n\nScore: 3/5\n"
+	if b.String() != want {
+		t.Errorf("printed\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	d := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	var got []string
+	for _, l := range d {
+		got = append(got, string(l.op)+l.text)
+	}
+	if s := strings.Join(got, ","); s != " a,-b,+x, c,+d" {
+		t.Errorf("diff = %s", s)
+	}
+}
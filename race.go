@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// raceLimits are the limits of the programs raceRun builds; the first
+// build with -race compiles the runtime again and can take a while.
+var raceLimits = sandboxLimits{Timeout: 2 * time.Minute, CPU: 20 * time.Second, MaxOutput: 1 << 20}
+
+// raceDemos are what 'gonutshell race' runs by default: the counter of
+// counterFact, and then one of the Counters lesson, called by 8 goroutines.
+var raceDemos = []string{
+	"hammer(counterFact(0), 8, 1000)",
+	"hammer(newMutexCounter(0).Next, 8, 1000)",
+}
+
+func cmdRace(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		args = raceDemos
+	}
+	for _, stmt := range args {
+		if err := raceRun(w, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// raceRun builds the tour with the race detector, with main printing the
+// value of the expression stmt, and runs it. It writes the value and a
+// line for each data race the race detector reports to w.
+func raceRun(w io.Writer, stmt string) error {
+	t, err := loadTour()
+	if err != nil {
+		return err
+	}
+	files, err := tourProgram(t.mainEdits(fmt.Sprintf("fmt.Fprintln(os.Stdout, %s)", stmt)))
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "gonutshell-race-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithTimeout(context.Background(), raceLimits.Timeout)
+	defer cancel()
+	prog := filepath.Join(dir, "prog")
+	if err := buildSandboxed(ctx, files, prog, "-race"); err != nil {
+		return fmt.Errorf("go build -race: %v", err)
+	}
+	var out bytes.Buffer
+	err = runProgram(ctx, prog, raceLimits, &out)
+	value, races := parseRaceOutput(out.String())
+	var ee *exec.ExitError
+	if err != nil && !(errors.As(err, &ee) && len(races) > 0) {
+		return fmt.Errorf("%s: %v\n%s", stmt, err, out.String())
+	}
+	fmt.Fprintf(w, "go run -race: %s = %s\n", stmt, strings.Join(value, " "))
+	for _, r := range races {
+		fmt.Fprintf(w, "  data race: %s\n", r)
+	}
+	if len(races) == 0 {
+		fmt.Fprintln(w, "  no data race")
+	}
+	return nil
+}
+
+// raceAccess is an access to a variable in a data race report.
+type raceAccess struct {
+	kind string // such as "read" or "previous write"
+	fn   string // the function making it, without "main."
+	pos  string // file:line, the file without its directory
+}
+
+func (a raceAccess) String() string {
+	return fmt.Sprintf("%s at %s in %s", a.kind, a.pos, a.fn)
+}
+
+// raceReport is a data race: two accesses to a variable, at least one a
+// write, from goroutines that did not synchronize.
+type raceReport struct {
+	access, previous raceAccess
+}
+
+func (r raceReport) String() string {
+	return r.access.String() + ", " + r.previous.String()
+}
+
+// raceAccessPattern matches the line of a race report naming an access.
+var raceAccessPattern = regexp.MustCompile(`^((?:Previous )?(?:[Aa]tomic )?(?:[Rr]ead|[Ww]rite)) at 0x[0-9a-f]+ by .*:$`)
+
+// parseRaceOutput splits the output of a program built with -race into the
+// lines the program printed and the data races reported.
+func parseRaceOutput(out string) (printed []string, races []raceReport) {
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	inReport := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case line == "==================":
+			inReport = !inReport
+			if inReport {
+				races = append(races, raceReport{})
+			}
+		case !inReport:
+			if !strings.HasPrefix(line, "Found ") || !strings.HasSuffix(line, " data race(s)") {
+				printed = append(printed, line)
+			}
+		case raceAccessPattern.MatchString(line) && i+2 < len(lines):
+			a := raceAccess{
+				kind: strings.ToLower(raceAccessPattern.FindStringSubmatch(line)[1]),
+				fn:   strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(lines[i+1]), "()"), "main."),
+				pos:  filepath.Base(strings.Fields(lines[i+2])[0]),
+			}
+			if r := &races[len(races)-1]; r.access == (raceAccess{}) {
+				r.access = a
+			} else if r.previous == (raceAccess{}) {
+				r.previous = a
+			}
+			i += 2
+		}
+	}
+	return printed, races
+}
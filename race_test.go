@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseRaceOutput(t *testing.T) {
+	out := `==================
+WARNING: DATA RACE
+Read at 0x00c000018178 by goroutine 8:
+  main.counterFact.func1()
+      /tmp/gonutshell-build-1/gonutshell.go:1355 +0x37
+  main.hammer.func1()
+      /tmp/gonutshell-build-1/counters.go:228 +0x4a
+
+Previous write at 0x00c000018178 by goroutine 11:
+  main.counterFact.func1()
+      /tmp/gonutshell-build-1/gonutshell.go:1355 +0x49
+
+Goroutine 8 (running) created at:
+  sync.(*WaitGroup).Go()
+      /usr/local/go/src/sync/waitgroup.go:238 +0x72
+==================
+7981
+Found 1 data race(s)
+`
+	printed, races := parseRaceOutput(out)
+	if strings.Join(printed, "\n") != "7981" {
+		t.Errorf("printed %q, want 7981", printed)
+	}
+	want := "read at gonutshell.go:1355 in counterFact.func1, previous write at gonutshell.go:1355 in counterFact.func1"
+	if len(races) != 1 || races[0].String() != want {
+		t.Errorf("races are %v, want %s", races, want)
+	}
+}
+
+func TestRaceRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the tour with -race")
+	}
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("-race needs cgo and a C compiler")
+	}
+	var b strings.Builder
+	if err := raceRun(&b, "hammer(counterFact(0), 8, 1000)"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "data race: read at gonutshell.go:") || !strings.Contains(b.String(), "in counterFact.func1") {
+		t.Errorf("no race reported in counterFact:\n%s", b.String())
+	}
+	b.Reset()
+	if err := raceRun(&b, "hammer(newAtomicCounter(0).Next, 8, 1000)"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "go run -race: hammer(newAtomicCounter(0).Next, 8, 1000) = 8000\n  no data race\n"; b.String() != want {
+		t.Errorf("raceRun printed\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// sandboxGoVersion is the go directive of the temporary modules, the oldest
+// release with everything the lessons use.
+const sandboxGoVersion = "1.25"
+
+// sandboxLimits bound what a program run in the sandbox may take.
+type sandboxLimits struct {
+	Timeout   time.Duration // for building and running the program together
+	CPU       time.Duration // of processor time for the program, if the shell can limit it
+	MaxOutput int           // bytes of output passed on before it is cut
+
+	// AllowNetwork runs programs where isolatedNetwork cannot take the
+	// network away from them, rather than refusing to.
+	AllowNetwork bool
+}
+
+// errNetwork is the refusal to run a program with network access.
+var errNetwork = errors.New("unshare cannot take the network away from edited programs here, run with -allow-network to run them with it")
+
+// checkNetwork returns errNetwork if programs would run within lim with
+// network access they are not allowed.
+func (lim sandboxLimits) checkNetwork() error {
+	if !lim.AllowNetwork && !isolatedNetwork() {
+		return errNetwork
+	}
+	return nil
+}
+
+// defaultLimits are the limits of programs run from the playground and by
+// the try command.
+var defaultLimits = sandboxLimits{Timeout: 20 * time.Second, CPU: 5 * time.Second, MaxOutput: 64 << 10}
+
+// buildError is a program that did not compile; Output is what the go
+// command said about it.
+type buildError struct {
+	Output string
+}
+
+func (e *buildError) Error() string {
+	return "build failed:\n" + e.Output
+}
+
+// errOutputLimit stops a program that printed more than its limit.
+var errOutputLimit = errors.New("output limit reached")
+
+// limitWriter passes at most n bytes on to w. Past that it calls stop and
+// fails.
+type limitWriter struct {
+	w       io.Writer
+	n       int
+	stop    func()
+	stopped bool
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.n {
+		lw.w.Write(p[:lw.n])
+		lw.n, lw.stopped = 0, true
+		lw.stop()
+		return 0, errOutputLimit
+	}
+	lw.n -= len(p)
+	return lw.w.Write(p)
+}
+
+// sandboxEnv is the environment of the go command and of the program: the
+// toolchain must not download modules or other toolchains.
+func sandboxEnv() []string {
+	return append(os.Environ(),
+		"GOPROXY=off",
+		"GOSUMDB=off",
+		"GOTOOLCHAIN=local",
+		"GOWORK=off",
+		"GOFLAGS=-mod=mod",
+		"CGO_ENABLED=0",
+	)
+}
+
+// runSandboxed writes files into a temporary module, builds it with the
+// local go toolchain and runs it, copying its output to stdout within lim.
+func runSandboxed(ctx context.Context, files map[string]string, lim sandboxLimits, stdout io.Writer) error {
+	if err := lim.checkNetwork(); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "gonutshell-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithTimeout(ctx, lim.Timeout)
+	defer cancel()
+	prog := filepath.Join(dir, "prog")
+	if err := buildSandboxed(ctx, files, prog); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("build timed out after %v", lim.Timeout)
+		}
+		return err
+	}
+	return runProgram(ctx, prog, lim, stdout)
+}
+
+// buildSandboxed builds files as the main package of a temporary module
+// into the executable prog, with the go build flags given.
+func buildSandboxed(ctx context.Context, files map[string]string, prog string, flags ...string) error {
+	dir, err := os.MkdirTemp("", "gonutshell-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	gomod := "module playground\n\ngo " + sandboxGoVersion + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644); err != nil {
+		return err
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			return err
+		}
+	}
+	var out bytes.Buffer
+	build := exec.CommandContext(ctx, "go", slices.Concat([]string{"build", "-o", prog}, flags, []string{"."})...)
+	build.Dir, build.Env = dir, sandboxEnv()
+	if slices.Contains(flags, "-race") {
+		build.Env = append(build.Env, "CGO_ENABLED=1") // the race detector needs cgo
+	}
+	build.Stdout, build.Stderr = &out, &out
+	if err := build.Run(); err != nil {
+		return &buildError{Output: out.String()}
+	}
+	return nil
+}
+
+// runProgram runs the executable prog within lim, copying its output to
+// stdout. The time limit is the one of ctx when it has one already. The
+// program runs in a temporary directory of its own, as the files it leaves
+// should not end up next to prog, in the cache of the packs say.
+func runProgram(ctx context.Context, prog string, lim sandboxLimits, stdout io.Writer) error {
+	dir, err := os.MkdirTemp("", "gonutshell-run-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lim.Timeout)
+		defer cancel()
+	}
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	lw := &limitWriter{w: stdout, n: lim.MaxOutput, stop: stop}
+	run := sandboxCommand(runCtx, prog, lim)
+	run.Dir, run.Env = dir, sandboxEnv()
+	run.Stdout, run.Stderr = lw, lw
+	err = run.Run()
+	switch {
+	case lw.stopped:
+		return fmt.Errorf("stopped after %d bytes of output", lim.MaxOutput)
+	case ctx.Err() != nil:
+		return fmt.Errorf("stopped after running for %v", lim.Timeout)
+	case lim.CPU > 0 && signaled(err):
+		return fmt.Errorf("stopped after using %v of CPU time", lim.CPU)
+	}
+	return err
+}
+
+// sandboxCommand returns the command running prog. Its CPU time is limited
+// with the ulimit of a POSIX shell, where there is one, and it runs
+// without a network where isolatedNetwork can give it none. Elsewhere,
+// on macOS and Windows say, the program can use the network, which
+// runSandboxed only lets it do with AllowNetwork.
+func sandboxCommand(ctx context.Context, prog string, lim sandboxLimits) *exec.Cmd {
+	args := []string{prog}
+	if sh, err := exec.LookPath("sh"); lim.CPU > 0 && err == nil {
+		secs := int((lim.CPU + time.Second - 1) / time.Second)
+		args = []string{sh, "-c", fmt.Sprintf(`ulimit -t %d && exec "$0"`, secs), prog}
+	}
+	if isolatedNetwork() {
+		args = append(slices.Clone(unshareNet), args...)
+	}
+	return exec.CommandContext(ctx, args[0], args[1:]...)
+}
+
+// unshareNet runs a command in network and user namespaces of its own: it
+// has a loopback interface, down, and no other.
+var unshareNet = []string{"unshare", "--net", "--map-root-user"}
+
+// isolatedNetwork reports whether unshareNet works, which takes Linux
+// with unprivileged user namespaces.
+var isolatedNetwork = sync.OnceValue(func() bool {
+	return exec.Command(unshareNet[0], append(unshareNet[1:], "true")...).Run() == nil
+})
+
+// signaled reports whether err is the exit of a process killed by a
+// signal, which is how the kernel stops it at its CPU limit.
+func signaled(err error) bool {
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) {
+		return false
+	}
+	ws, ok := ee.Sys().(syscall.WaitStatus)
+	return ok && ws.Signaled()
+}
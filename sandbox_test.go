@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSandboxed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	files := map[string]string{"main.go": "package main\n\nfunc main() {}\n"}
+	if err := buildSandboxed(t.Context(), files, filepath.Join(t.TempDir(), "prog")); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("the files given are %d after the build, not 1", len(files))
+	}
+}
+
+func TestRunProgramDir(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	src := `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	_, err := os.Stat("left")
+	fmt.Println(err == nil)
+	os.WriteFile("left", nil, 0o644)
+}
+`
+	prog := filepath.Join(t.TempDir(), "prog")
+	if err := buildSandboxed(t.Context(), map[string]string{"main.go": src}, prog); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		var b strings.Builder
+		if err := runProgram(t.Context(), prog, defaultLimits, &b); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != "false\n" {
+			t.Error("the program found the file of its previous run")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(prog), "left")); err == nil {
+		t.Error("the program left a file next to its executable")
+	}
+}
+
+func TestSandboxNetwork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	if !isolatedNetwork() {
+		t.Skip("unshare cannot make a network namespace here")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	src := fmt.Sprintf(`package main
+
+import (
+	"fmt"
+	"net"
+)
+
+func main() {
+	_, err := net.Dial("tcp", %q)
+	fmt.Println(err != nil)
+}
+`, l.Addr())
+	var b strings.Builder
+	if err := runSandboxed(t.Context(), map[string]string{"main.go": src}, defaultLimits, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "true\n" {
+		t.Errorf("the program reached %s on the network of the test", l.Addr())
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// searchField is the part of a step a line of text comes from.
+type searchField int
+
+const (
+	fieldHeading searchField = iota
+	fieldComment
+	fieldCode
+	fieldOutput
+)
+
+// fieldWeights is how much a term counts depending on where it is found:
+// headings say what a step is about, the narrative explains it, the code
+// and the output only mention it.
+var fieldWeights = [...]float64{
+	fieldHeading: 4,
+	fieldComment: 2,
+	fieldCode:    1,
+	fieldOutput:  0.5,
+}
+
+var fieldNames = [...]string{
+	fieldHeading: "heading",
+	fieldComment: "note",
+	fieldCode:    "code",
+	fieldOutput:  "output",
+}
+
+// searchLine is a line of a step shown in search results.
+type searchLine struct {
+	field searchField
+	text  string
+}
+
+// searchDoc is a step of a lesson as the search index sees it.
+type searchDoc struct {
+	lesson Lesson
+	step   int // counted from 1
+	lines  []searchLine
+	terms  map[string]float64 // weighted term frequencies
+}
+
+// searchIndex is an inverted index of the steps of the lessons.
+type searchIndex struct {
+	docs     []*searchDoc
+	postings map[string][]*searchDoc
+}
+
+// buildSearchIndex indexes the headings, comments, code and output of the
+// steps of ls.
+func buildSearchIndex(ls []Lesson) (*searchIndex, error) {
+	ix := &searchIndex{postings: map[string][]*searchDoc{}}
+	for _, l := range ls {
+		steps, err := lessonSteps(l)
+		if err != nil {
+			return nil, err
+		}
+		out := captureLesson(l)
+		for i, s := range steps {
+			d := &searchDoc{lesson: l, step: i + 1, terms: map[string]float64{}}
+			d.add(fieldHeading, s.Heading)
+			for _, n := range s.Notes {
+				for line := range strings.Lines(n) {
+					d.add(fieldComment, line)
+				}
+			}
+			d.addCode(s.Code)
+			for line := range strings.Lines(s.output(out)) {
+				d.add(fieldOutput, line)
+			}
+			ix.docs = append(ix.docs, d)
+			for t := range d.terms {
+				ix.postings[t] = append(ix.postings[t], d)
+			}
+		}
+	}
+	return ix, nil
+}
+
+// add indexes a line of text found in field f and keeps it for display.
+func (d *searchDoc) add(f searchField, line string) {
+	line = strings.TrimRight(line, "\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	d.lines = append(d.lines, searchLine{f, line})
+	d.count(f, line)
+}
+
+func (d *searchDoc) count(f searchField, text string) {
+	for _, t := range searchTerms(text) {
+		d.terms[t] += fieldWeights[f]
+	}
+}
+
+// addCode indexes the comments in code as narrative and its identifiers,
+// keywords and literals as code.
+func (d *searchDoc) addCode(code string) {
+	for line := range strings.Lines(code) {
+		if line = strings.TrimRight(line, "\n"); strings.TrimSpace(line) != "" {
+			d.lines = append(d.lines, searchLine{fieldCode, line})
+		}
+	}
+	var sc scanner.Scanner
+	src := []byte(code)
+	sc.Init(token.NewFileSet().AddFile("", -1, len(src)), src, nil, scanner.ScanComments)
+	for {
+		_, tok, lit := sc.Scan()
+		switch {
+		case tok == token.EOF:
+			return
+		case tok == token.COMMENT:
+			d.count(fieldComment, lit)
+		case tok == token.IDENT || tok == token.STRING || tok == token.CHAR:
+			d.count(fieldCode, lit)
+		case tok.IsKeyword():
+			d.count(fieldCode, tok.String())
+		}
+	}
+}
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// searchTerms splits text into the terms the index is made of: its words
+// in lower case, with identifiers such as circClosed also split into their
+// parts and a plural 's' dropped, so that "runes" finds "rune".
+func searchTerms(text string) []string {
+	var terms []string
+	for _, w := range wordPattern.FindAllString(text, -1) {
+		parts := identParts(w)
+		if len(parts) > 1 {
+			parts = append(parts, w)
+		}
+		for _, p := range parts {
+			if t := stem(strings.ToLower(p)); len(t) > 1 {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return terms
+}
+
+// identParts splits an identifier at underscores and where a lower case
+// letter is followed by an upper case one.
+func identParts(id string) []string {
+	var parts []string
+	start := 0
+	prev := rune(0)
+	for i, r := range id {
+		switch {
+		case r == '_':
+			parts = append(parts, id[start:i])
+			start = i + 1
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			parts = append(parts, id[start:i])
+			start = i
+		}
+		prev = r
+	}
+	parts = append(parts, id[start:])
+	return slices.DeleteFunc(parts, func(p string) bool { return p == "" })
+}
+
+func stem(t string) string {
+	if len(t) > 3 && strings.HasSuffix(t, "s") && !strings.HasSuffix(t, "ss") {
+		return t[:len(t)-1]
+	}
+	return t
+}
+
+// searchHit is a step found by a search.
+type searchHit struct {
+	doc   *searchDoc
+	score float64
+}
+
+// search ranks the steps containing any term of query by the weighted
+// frequency of the terms in them, rare terms counting for more, and by the
+// share of the terms they contain.
+func (ix *searchIndex) search(query string) []searchHit {
+	terms := slices.Compact(slices.Sorted(slices.Values(searchTerms(query))))
+	scores := map[*searchDoc]float64{}
+	matched := map[*searchDoc]int{}
+	for _, t := range terms {
+		docs := ix.postings[t]
+		idf := math.Log(1 + float64(len(ix.docs))/float64(len(docs)+1))
+		for _, d := range docs {
+			scores[d] += d.terms[t] * idf
+			matched[d]++
+		}
+	}
+	var hits []searchHit
+	for _, d := range ix.docs { // in tour order, for stable ties
+		if s, ok := scores[d]; ok {
+			hits = append(hits, searchHit{d, s * float64(matched[d]) / float64(len(terms))})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b searchHit) int { return cmp.Compare(b.score, a.score) })
+	return hits
+}
+
+// matchingLines returns up to n lines of the hit containing the terms of
+// query, the most telling ones first.
+func (h searchHit) matchingLines(query []string, n int) []searchLine {
+	var lines []searchLine
+	for _, l := range h.doc.lines {
+		if l.field != fieldHeading && slices.ContainsFunc(searchTerms(l.text), func(t string) bool { return slices.Contains(query, t) }) {
+			lines = append(lines, l)
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b searchLine) int { return cmp.Compare(a.field, b.field) })
+	return lines[:min(n, len(lines))]
+}
+
+// highlight marks the words of line that contain a term of query with mark
+// and unmark.
+func highlight(line string, query []string, mark, unmark string) string {
+	return wordPattern.ReplaceAllStringFunc(line, func(w string) string {
+		if slices.ContainsFunc(searchTerms(w), func(t string) bool { return slices.Contains(query, t) }) {
+			return mark + w + unmark
+		}
+		return w
+	})
+}
+
+// isTerminal reports whether w is a terminal, where the matches are
+// highlighted in color.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func cmdSearch(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(w)
+	n := fs.Int("n", 10, "show at most `count` results")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return errors.New("usage: gonutshell search [-n count] <query>")
+	}
+	ix, err := buildSearchIndex(lessons)
+	if err != nil {
+		return err
+	}
+	hits := ix.search(query)
+	if len(hits) == 0 {
+		fmt.Fprintf(w, "Nothing found for %q.\n", query)
+		return nil
+	}
+	mark, unmark := "*", "*"
+	if isTerminal(w) {
+		mark, unmark = "\x1b[1;33m", "\x1b[0m"
+	}
+	for i, h := range hits[:min(*n, len(hits))] {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		d := h.doc
+		fmt.Fprintf(w, "%s/%d  %s\n", d.lesson.ID, d.step, highlight(d.lines[0].text, terms, mark, unmark))
+		for _, l := range h.matchingLines(terms, 3) {
+			fmt.Fprintf(w, "    %-7s %s\n", fieldNames[l.field]+":", highlight(collapseSpace(l.text), terms, mark, unmark))
+		}
+		fmt.Fprintf(w, "    gonutshell step %s %d\n", d.lesson.ID, d.step)
+	}
+	if len(hits) > *n {
+		fmt.Fprintf(w, "\n%d more, see -n.\n", len(hits)-*n)
+	}
+	return nil
+}
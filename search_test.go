@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("circClosed wraps the runes, a b_c")
+	want := []string{"circ", "closed", "circclosed", "wrap", "the", "rune", "b_c"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := highlight("a rune is an int32; runes", []string{"rune"}, "<", ">"), "a <rune> is an int32; <runes>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	quickBenchmarks(t)
+	ix, err := buildSearchIndex(lessons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ query, top string }{
+		{"runes", "strings/3"},
+		{"closures", "first-class/1"},
+		{"copy sub-range", "slices/5"},
+		{"strings are immutable", "strings/8"},
+	} {
+		hits := ix.search(tt.query)
+		if len(hits) == 0 {
+			t.Errorf("nothing found for %q", tt.query)
+			continue
+		}
+		var top []string
+		for _, h := range hits[:min(3, len(hits))] {
+			top = append(top, fmt.Sprintf("%s/%d", h.doc.lesson.ID, h.doc.step))
+		}
+		if top[0] != tt.top {
+			t.Errorf("search for %q ranks %s, want %s first", tt.query, strings.Join(top, " "), tt.top)
+		}
+	}
+	if hits := ix.search("zyzzyva"); len(hits) != 0 {
+		t.Errorf("found %d steps for a word in none of them", len(hits))
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSnippet is the size of the largest snippet the playground accepts.
+const maxSnippet = 64 << 10
+
+// playground is the web playground: an index of the lessons, a page per
+// lesson with its steps in editable text areas, and endpoints running a
+// lesson or one of its steps, edited or not.
+//
+// As the endpoints run any code they are sent, they only answer requests
+// for the playground's own host from its own pages: requests for another
+// host name are turned down, so that a site whose name points at the
+// machine cannot reach the playground, cross-origin requests are too, and
+// a run needs the token of the playground, which its pages send along.
+type playground struct {
+	host    string // the host of the address served, besides loopback ones
+	token   string
+	handler http.Handler
+	doc     func() (*tourDoc, error) // of the pages, built once without running the lessons
+	limits  sandboxLimits            // of the edited steps
+}
+
+// runTokenHeader is the header carrying the token of a playground.
+const runTokenHeader = "X-Run-Token"
+
+// newPlayground returns the playground served on addr.
+func newPlayground(addr string) *playground {
+	p := &playground{token: rand.Text(), limits: defaultLimits}
+	p.host, _, _ = net.SplitHostPort(addr)
+	p.doc = sync.OnceValues(func() (*tourDoc, error) { return buildTourDoc(lessons, false) })
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", p.serveIndex)
+	mux.HandleFunc("GET /{page}", p.serveLessonPage)
+	mux.Handle("POST /run/{id}", p.checkToken(serveRunLesson))
+	mux.Handle("POST /run/{id}/{step}", p.checkToken(p.serveRunStep))
+	p.handler = http.NewCrossOriginProtection().Handler(mux)
+	return p
+}
+
+func (p *playground) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	ip := net.ParseIP(host)
+	if host != "localhost" && !(ip != nil && ip.IsLoopback()) && (host != p.host || p.host == "") {
+		http.Error(w, fmt.Sprintf("host %q is not the playground's", host), http.StatusMisdirectedRequest)
+		return
+	}
+	p.handler.ServeHTTP(w, r)
+}
+
+// checkToken turns down the requests without the token of p.
+func (p *playground) checkToken(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(runTokenHeader)), []byte(p.token)) != 1 {
+			http.Error(w, "not sent from a page of the playground", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	})
+}
+
+var playgroundTemplates = template.Must(template.Must(htmlTemplates.Clone()).Funcs(template.FuncMap{
+	"inc":   func(i int) int { return i + 1 },
+	"lines": func(s string) int { return strings.Count(s, "\n") + 1 },
+}).Parse(`
+{{define "playground"}}{{template "head" .Lesson.Title}}<p><a href="/">{{.Title}}</a></p>
+<h1>{{.Lesson.Title}}</h1>
+<p><button data-url="/run/{{.Lesson.ID}}" onclick="run(this)">Run the whole lesson</button></p>
+<pre class="output" hidden></pre>
+{{range $i, $s := .Lesson.Steps}}{{if eq .Level 1}}<h2>{{.Heading}}</h2>{{else}}<h3>{{.Heading}}</h3>{{end}}
+{{range .Notes}}<p class="note">{{.}}</p>
+{{end}}{{if .Code}}<textarea spellcheck="false" rows="{{lines .Code}}">{{.Code}}</textarea>
+<p><button data-url="/run/{{$.Lesson.ID}}/{{inc $i}}" onclick="run(this)">Run</button>
+<button onclick="reset(this)">Reset</button></p>
+<pre class="output" hidden></pre>
+{{end}}{{end}}<nav>
+<span>{{with .Lesson.Prev}}&larr; <a href="{{.ID}}.html">{{.Title}}</a>{{end}}</span>
+<span>{{with .Lesson.Next}}<a href="{{.ID}}.html">{{.Title}}</a> &rarr;{{end}}</span>
+</nav>
+<style>textarea { width: 100%; font-family: monospace; tab-size: 4; }</style>
+<script>
+// run posts the code above the button and streams the output below it
+async function run(btn) {
+	const p = btn.parentElement, code = p.previousElementSibling, out = p.nextElementSibling;
+	out.hidden = false;
+	out.textContent = "";
+	const resp = await fetch(btn.dataset.url, {
+		method: "POST",
+		headers: {"{{.TokenHeader}}": "{{.Token}}"},
+		body: code.tagName == "TEXTAREA" ? code.value : "",
+	});
+	const reader = resp.body.getReader(), dec = new TextDecoder();
+	for (;;) {
+		const {done, value} = await reader.read();
+		if (done) break;
+		out.textContent += dec.decode(value, {stream: true});
+	}
+}
+function reset(btn) {
+	const code = btn.parentElement.previousElementSibling;
+	code.value = code.defaultValue;
+}
+</script>
+</body>
+</html>
+{{end}}
+`))
+
+func (p *playground) serveIndex(w http.ResponseWriter, r *http.Request) {
+	doc, err := p.doc()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	playgroundTemplates.ExecuteTemplate(w, "index", doc)
+}
+
+func (p *playground) serveLessonPage(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(r.PathValue("page"), ".html")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	doc, err := p.doc()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, l := range doc.Lessons {
+		if l.ID == id {
+			playgroundTemplates.ExecuteTemplate(w, "playground", struct {
+				*tourDoc
+				Lesson             *docLesson
+				TokenHeader, Token string
+			}{doc, l, runTokenHeader, p.token})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// flushWriter sends everything written to it to the client right away.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err == nil {
+		err = fw.rc.Flush()
+	}
+	return n, err
+}
+
+func streamingOutput(w http.ResponseWriter) io.Writer {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	return flushWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+func serveRunLesson(w http.ResponseWriter, r *http.Request) {
+	l, ok := findLesson(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	l.Run(lessonWriter(streamingOutput(w)))
+}
+
+// serveRunStep runs a step with the code in the request body. The output
+// of an unchanged step is taken from a run of the lesson in process; an
+// edited one is built and run in the sandbox, if its limits allow.
+func (p *playground) serveRunStep(w http.ResponseWriter, r *http.Request) {
+	l, ok := findLesson(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	steps, err := lessonSteps(l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("step"))
+	if err != nil || n < 1 || n > len(steps) {
+		http.NotFound(w, r)
+		return
+	}
+	s := steps[n-1]
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSnippet))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	code := strings.TrimSpace(strings.ReplaceAll(string(body), "\r\n", "\n"))
+
+	if code == strings.TrimSpace(s.Code) {
+		io.WriteString(streamingOutput(w), s.output(captureLesson(l)))
+		return
+	}
+	if err := p.limits.checkNetwork(); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	out := streamingOutput(w)
+	if err := runEditedStep(r.Context(), l, s, code, p.limits, out); err != nil {
+		fmt.Fprintf(out, "\n%v\n", err)
+	}
+}
+
+func cmdServe(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(w)
+	addr := fs.String("addr", "localhost:8080", "listen on `host:port`")
+	allowNetwork := fs.Bool("allow-network", false, "run edited steps with network access where it cannot be taken away from them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p := newPlayground(*addr)
+	p.limits.AllowNetwork = *allowNetwork
+	fmt.Fprintf(w, "Serving the tour on http://%s/\n", *addr)
+	switch {
+	case isolatedNetwork():
+	case *allowNetwork:
+		fmt.Fprintln(w, "Note: edited steps run with network access, unshare cannot give them a network namespace here.")
+	default:
+		fmt.Fprintln(w, "Note: edited steps will not run, unshare cannot give them a network namespace here; -allow-network runs them with network access.")
+	}
+	srv := &http.Server{Addr: *addr, Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	return srv.ListenAndServe()
+}
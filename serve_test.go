@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// post sends code to a run endpoint of the playground p served by srv, as
+// its pages do.
+func post(t *testing.T, srv *httptest.Server, p *playground, path, code string) (int, string) {
+	req, _ := http.NewRequest("POST", srv.URL+path, strings.NewReader(code))
+	req.Header.Set(runTokenHeader, p.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestPlayground(t *testing.T) {
+	p := newPlayground("localhost:8080")
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		method, path, body string
+		status             int
+		want               string
+	}{
+		{"GET", "/", "", 200, `<a href="slices.html">Slices</a>`},
+		{"GET", "/slices.html", "", 200, "o1 := []int{1, 2, 3, 4, 5}"},
+		{"GET", "/nope.html", "", 404, ""},
+		{"POST", "/run/maps", "", 200, "map[Alan:83 Cathy:91]"},
+		{"POST", "/run/slices/99", "", 404, ""},
+	} {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+		req.Header.Set(runTokenHeader, p.token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+		if !strings.Contains(string(body), tt.want) {
+			t.Errorf("%s %s: body does not contain %q:\n%s", tt.method, tt.path, tt.want, body)
+		}
+	}
+
+	// an unchanged step, as a browser sends it, is not built again
+	l, _ := findLesson("slices")
+	steps, err := lessonSteps(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := steps[4]
+	_, body := post(t, srv, p, "/run/slices/5", strings.ReplaceAll(s.Code, "\n", "\r\n"))
+	if want := s.output(captureLesson(l)); body != want || want == "" {
+		t.Errorf("unchanged step 5 of slices: got %q, want %q", body, want)
+	}
+}
+
+func TestPlaygroundEdited(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds programs with the go command")
+	}
+	p := newPlayground("localhost:8080")
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	for _, tt := range []struct{ code, want string }{
+		{"o2 := []int{1, 2, 3, 4, 5}\ncopy(o2[1:4], e1[2:])\nfmt.Fprintln(w, o2)", "[1 30 40 4 5]\n"},
+		{"x := 1\nfmt.Fprintln(w, o2)", "line 1:1: declared and not used: x"},
+	} {
+		if _, body := post(t, srv, p, "/run/slices/5", tt.code); !strings.Contains(body, tt.want) {
+			t.Errorf("running %q: output does not contain %q:\n%s", tt.code, tt.want, body)
+		}
+	}
+}
+
+func TestPlaygroundGuard(t *testing.T) {
+	p := newPlayground("tour.example:8080")
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		name   string
+		host   string
+		header map[string]string
+		status int
+	}{
+		{"own page", "", map[string]string{runTokenHeader: p.token}, 200},
+		{"served host", "tour.example:8080", map[string]string{runTokenHeader: p.token}, 200},
+		{"no token", "", nil, 403},
+		{"wrong token", "", map[string]string{runTokenHeader: "guess"}, 403},
+		{"other site", "", map[string]string{runTokenHeader: p.token, "Origin": "http://evil.example", "Sec-Fetch-Site": "cross-site"}, 403},
+		{"rebound name", "evil.example:8080", map[string]string{runTokenHeader: p.token}, 421},
+	} {
+		req, _ := http.NewRequest("POST", srv.URL+"/run/maps", strings.NewReader(""))
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/maps.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), p.token) {
+		t.Error("the lesson page does not carry the token")
+	}
+}
+
+func TestPlaygroundNetwork(t *testing.T) {
+	defer func(f func() bool) { isolatedNetwork = f }(isolatedNetwork)
+	isolatedNetwork = func() bool { return false }
+	p := newPlayground("localhost:8080")
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	code := "fmt.Fprintln(w, len(e1))"
+	if status, body := post(t, srv, p, "/run/slices/5", code); status != 403 || !strings.Contains(body, "-allow-network") {
+		t.Errorf("without a network namespace: status %d, body %q", status, body)
+	}
+	if testing.Short() {
+		return
+	}
+	p.limits.AllowNetwork = true
+	if status, body := post(t, srv, p, "/run/slices/5", code); status != 200 || body != "4\n" {
+		t.Errorf("with -allow-network: status %d, body %q", status, body)
+	}
+}
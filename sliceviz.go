@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func lessonSliceInternals(w io.Writer) {
+	// ==== Slice headers & backing arrays
+	/*
+		A slice is a small header of three words: a pointer to an element
+		of a backing array, the length and the capacity. Assigning or
+		passing a slice copies the header, never the array, which is why
+		the slice operations of the Slices lesson can affect each other.
+		The diagrams below are drawn from the real headers: '=' marks the
+		elements within the length of a slice, '.' the rest of its capacity.
+	*/
+	v := newSliceViz(w)
+
+	// --- append grows the backing array
+	var s1 []int
+	v.show("var s1 []int", "s1", s1)
+	s1 = append(s1, 1, 3, 5, 7)
+	v.show("s1 = append(s1, 1, 3, 5, 7)", "s1", s1)
+	s1 = append(s1, 9)
+	v.show("s1 = append(s1, 9)", "s1", s1)
+	s1 = append(s1, 11)
+	v.show("s1 = append(s1, 11)", "s1", s1)
+	/*
+		When the capacity runs out append allocates a bigger array, about
+		twice as big for small slices, and copies the elements over. Until
+		then it writes into the spare capacity of the array it has.
+	*/
+
+	// --- sub-slices share the array
+	o2 := []int{1, 2, 3, 4, 5}
+	o3 := o2[1:4]
+	v.show("o3 := o2[1:4]", "o2 o3", o2, o3)
+	o3[0] = 20
+	fmt.Fprintln(w, "o2 =", o2) // Output: o2 = [1 20 3 4 5]
+	// NOTE: o3[0] and o2[1] are the same element
+
+	// --- append through a sub-slice
+	o3 = append(o3, 40)
+	v.show("o3 = append(o3, 40)", "o2 o3", o2, o3)
+	fmt.Fprintln(w, "o2 =", o2) // Output: o2 = [1 20 3 4 40]
+	o3 = append(o3, 50)
+	v.show("o3 = append(o3, 50)", "o2 o3", o2, o3)
+	/*
+		o3 had room for one more element, the one o2 still uses, so the
+		first append overwrote o2[4]. The second needed more than the
+		capacity and moved o3 to an array of its own.
+	*/
+
+	// --- full slice expression limits the capacity
+	o4 := o2[1:3:3]
+	v.show("o4 := o2[1:3:3]", "o2 o4", o2, o4)
+	o4 = append(o4, 99)
+	v.show("o4 = append(o4, 99)", "o2 o4", o2, o4)
+	// NOTE: with cap(o4) == len(o4) append has to copy, o2 is untouched
+
+	// --- the delete idioms work in place
+	s2 := []int{1, 2, 3, 4, 5}
+	before := s2
+	copy(s2[1:], s2[2:])
+	s2[len(s2)-1] = 0
+	s2 = s2[:len(s2)-1]
+	v.show("delete s2[1], keeping the order", "before s2", before, s2)
+	/*
+		Deleting shifts the elements within the same array; another slice
+		of it, like 'before', sees them move and the last one zeroed.
+	*/
+}
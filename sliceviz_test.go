@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSliceViz(t *testing.T) {
+	var b strings.Builder
+	v := newSliceViz(&b)
+	a := []int{1, 2, 3, 4}
+	sub := a[2:3]
+	other := []int{7}
+	v.show("sub := a[2:3]", "a sub other", a, sub, other)
+	for _, want := range []string{
+		"  a      ptr=0x",
+		"  sub    ptr=0x",
+		"| 1 | 2 | 3 | 4 |\n",
+		"\n  a      ================\n  sub            ====....\n",
+		"a and sub share this array\n",
+		"| 7 |\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("diagram does not contain %q:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "other share") {
+		t.Errorf("other shares an array:\n%s", b.String())
+	}
+
+	b.Reset()
+	sub = append(sub, 5, 6)
+	v.show("sub = append(sub, 5, 6)", "a sub", a, sub)
+	if want := "<- new array, cap 2 -> "; !strings.Contains(b.String(), want) {
+		t.Errorf("reallocation not shown, no %q in:\n%s", want, b.String())
+	}
+}
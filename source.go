@@ -0,0 +1,87 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// tourFS holds the source of the tour so that the tooling commands can
+// look at the code and comments of the lessons they run.
+//
+//go:embed gonutshell.go sliceviz.go complexity.go counters.go tourtools.go
+var tourFS embed.FS
+
+// tourFiles lists the files in tourFS, in the order of the tour, with the
+// tooling of the lessons last.
+var tourFiles = []string{"gonutshell.go", "sliceviz.go", "complexity.go", "counters.go", toolingFile}
+
+// toolingFile is the tour file with the tooling of the lessons, code that
+// they call but do not teach.
+const toolingFile = "tourtools.go"
+
+// tourSource is the parsed source of the tour, comments included.
+type tourSource struct {
+	fset  *token.FileSet
+	files []*ast.File
+}
+
+var (
+	tourOnce   sync.Once
+	parsedTour *tourSource
+	tourErr    error
+)
+
+// loadTour parses the embedded tour source, once.
+func loadTour() (*tourSource, error) {
+	tourOnce.Do(func() {
+		t := &tourSource{fset: token.NewFileSet()}
+		for _, name := range tourFiles {
+			src, err := tourFS.ReadFile(name)
+			if err != nil {
+				tourErr = err
+				return
+			}
+			f, err := parser.ParseFile(t.fset, name, src, parser.ParseComments)
+			if err != nil {
+				tourErr = err
+				return
+			}
+			t.files = append(t.files, f)
+		}
+		parsedTour = t
+	})
+	return parsedTour, tourErr
+}
+
+// lessonFuncName returns the runtime name of the function behind l.Run,
+// e.g. "main.lessonSlices".
+func lessonFuncName(l Lesson) string {
+	return runtime.FuncForPC(reflect.ValueOf(l.Run).Pointer()).Name()
+}
+
+// lessonDecl finds the declaration of the function behind l.Run.
+func (t *tourSource) lessonDecl(l Lesson) (*ast.FuncDecl, *ast.File, error) {
+	// drop the package path, which is not "main" under go test
+	name := lessonFuncName(l)
+	name = name[strings.LastIndex(name, ".")+1:]
+	for _, f := range t.files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == name {
+				return fd, f, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("lesson %q: no source for %s", l.ID, name)
+}
+
+// line returns the line number of pos.
+func (t *tourSource) line(pos token.Pos) int {
+	return t.fset.Position(pos).Line
+}
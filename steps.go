@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// headingPattern matches the '// ==== Heading ====' and '// --- heading'
+// comments that split a lesson into steps. The first group tells the level.
+var headingPattern = regexp.MustCompile(`^//\s*(={3,}|-{3,})\s*(.*?)\s*(?:={3,}|-{3,})?\s*$`)
+
+// step is the part of a lesson from one heading comment up to the next.
+type step struct {
+	Heading    string
+	Level      int // 1 for '====' headings, 2 for '---' ones
+	Start, End int // lines of the heading and of the last line of code
+	Code       string
+	Notes      []string // the block comments that explain the code
+}
+
+// output returns what the code of s printed when its lesson wrote out.
+func (s step) output(out transcript) string {
+	return out.between(s.Start-1, s.End)
+}
+
+// lessonSteps splits the source of l at its heading comments. A heading
+// that is directly followed by another one, with no code in between, is
+// folded into the step of the next heading; a last step may consist of
+// notes only.
+func lessonSteps(l Lesson) ([]step, error) {
+	if l.pack != nil {
+		return slices.Clone(l.pack.steps), nil
+	}
+	t, err := loadTour()
+	if err != nil {
+		return nil, err
+	}
+	fd, f, err := t.lessonDecl(l)
+	if err != nil {
+		return nil, err
+	}
+	src, err := tourFS.ReadFile(t.fset.Position(f.Pos()).Filename)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(src), "\n")
+	first, last := t.line(fd.Body.Lbrace)+1, t.line(fd.Body.Rbrace)-1
+
+	var blocks []*ast.Comment
+	var steps []step
+	for _, g := range f.Comments {
+		if g.Pos() < fd.Body.Lbrace || g.End() > fd.Body.Rbrace {
+			continue
+		}
+		for _, c := range g.List {
+			if strings.HasPrefix(c.Text, "/*") {
+				blocks = append(blocks, c)
+				continue
+			}
+			m := headingPattern.FindStringSubmatch(c.Text)
+			if m == nil || strings.TrimSpace(lines[t.line(c.Pos())-1]) != c.Text {
+				continue
+			}
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			steps = append(steps, step{Heading: m[2], Level: level, Start: t.line(c.Pos())})
+		}
+	}
+	if len(steps) == 0 || steps[0].Start > first {
+		steps = append([]step{{Heading: l.Title, Level: 1, Start: first - 1}}, steps...)
+	}
+	for i := range steps {
+		steps[i].End = last
+		if i+1 < len(steps) {
+			steps[i].End = steps[i+1].Start - 1
+		}
+	}
+
+	hasCode := func(s step) bool {
+		for _, st := range fd.Body.List {
+			if l := t.line(st.Pos()); l >= s.Start && l <= s.End {
+				return true
+			}
+		}
+		return false
+	}
+	var merged []step
+	for i := 0; i < len(steps); i++ {
+		s := steps[i]
+		for !hasCode(s) && i+1 < len(steps) {
+			i++
+			s = step{Heading: s.Heading + ": " + steps[i].Heading, Level: s.Level, Start: s.Start, End: steps[i].End}
+		}
+		merged = append(merged, s)
+	}
+
+	// the code of a step is its source without the headings, which
+	// merged steps can have several of, and without its notes
+	skip := map[int]bool{}
+	for _, s := range steps {
+		skip[s.Start] = true
+	}
+	for i := range merged {
+		s := &merged[i]
+		for _, b := range blocks {
+			from, to := t.line(b.Pos()), t.line(b.End())
+			if from <= s.Start || to > s.End {
+				continue
+			}
+			s.Notes = append(s.Notes, commentText(b))
+			for n := from; n <= to; n++ {
+				skip[n] = true
+			}
+		}
+		var code []string
+		for n := s.Start + 1; n <= s.End; n++ {
+			if !skip[n] {
+				code = append(code, lines[n-1])
+			}
+		}
+		s.Code = dedent(strings.Join(code, "\n"))
+	}
+	return merged, nil
+}
+
+// commentText returns the text of a block comment, dedented.
+func commentText(c *ast.Comment) string {
+	return dedent(strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/"))
+}
+
+// dedent removes the indentation all non-blank lines of s share, along
+// with leading and trailing blank lines.
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	prefix, first := "", true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		ind := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = ind, false
+		}
+		for !strings.HasPrefix(ind, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(strings.TrimPrefix(l, prefix), " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stdin is where the interactive commands read the learner's answers from.
+var stdin io.Reader = os.Stdin
+
+func cmdStep(w io.Writer, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: gonutshell step <lesson> [step]")
+	}
+	l, ok := findLesson(args[0])
+	if !ok {
+		return fmt.Errorf("no lesson %q, see 'gonutshell list'", args[0])
+	}
+	start := 0
+	if len(args) == 2 {
+		steps, err := lessonSteps(l)
+		if err != nil {
+			return err
+		}
+		if start, err = stepArg(l, steps, args[1]); err != nil {
+			return err
+		}
+	}
+	return stepLesson(w, l, start)
+}
+
+// stepThrough shows the steps of l from start on, one at a time, until the
+// learner quits or gets past the end. It returns the index of the step the
+// learner was last shown and whether that was the end of the lesson.
+func stepThrough(w io.Writer, in *bufio.Scanner, l Lesson, steps []step, start int) (int, bool, error) {
+	out := captureLesson(l)
+	steps = localizeSteps(l, steps)
+	i := min(max(start, 0), len(steps)-1)
+	for {
+		printStep(w, l, steps, i, out)
+		fmt.Fprint(w, "\n[Enter] next, [b]ack, [q]uit > ")
+		if !in.Scan() {
+			fmt.Fprintln(w)
+			return i, false, in.Err()
+		}
+		switch strings.TrimSpace(in.Text()) {
+		case "q":
+			return i, false, nil
+		case "b":
+			i = max(i-1, 0)
+		default:
+			if i == len(steps)-1 {
+				fmt.Fprintf(w, "\nThat was the last step of %s.\n", l.ID)
+				return i, true, nil
+			}
+			i++
+		}
+	}
+}
+
+// printStep shows the code of step i with its notes and its output.
+func printStep(w io.Writer, l Lesson, steps []step, i int, out transcript) {
+	s := steps[i]
+	fmt.Fprintf(w, "\n[%s %d/%d] %s\n\n", l.ID, i+1, len(steps), s.Heading)
+	if s.Code != "" {
+		fmt.Fprintf(w, "%s\n\n", indent(s.Code, "    "))
+	}
+	for _, n := range s.Notes {
+		fmt.Fprintf(w, "%s\n\n", indent(n, "  | "))
+	}
+	fmt.Fprintln(w, "output:")
+	o := strings.TrimRight(s.output(out), "\n")
+	if o == "" {
+		o = "(nothing)"
+	}
+	fmt.Fprintln(w, indent(o, "    "))
+}
+
+// indent puts prefix in front of every line of s, and shows its tabs as
+// four blanks.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(prefix+strings.ReplaceAll(l, "\t", "    "), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findStep returns the index of the step with the given heading. For a step
+// that several headings were merged into, the last one also finds it.
+func findStep(steps []step, heading string) (int, bool) {
+	for i, s := range steps {
+		if s.Heading == heading || strings.HasSuffix(s.Heading, ": "+heading) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+// TestStepsCoverOutput checks that the steps of each lesson account for all
+// of its output, in order.
+func TestStepsCoverOutput(t *testing.T) {
+	quickBenchmarks(t)
+	for _, l := range lessons {
+		steps, err := lessonSteps(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := captureLesson(l)
+		var joined strings.Builder
+		for _, s := range steps {
+			if strings.TrimSpace(s.Code) == "" && len(s.Notes) == 0 {
+				t.Errorf("%s: step %q is empty", l.ID, s.Heading)
+			}
+			joined.WriteString(s.output(out))
+		}
+		if joined.String() != out.String() {
+			t.Errorf("%s: steps do not add up to the output of the lesson", l.ID)
+		}
+	}
+}
+
+func TestStepThrough(t *testing.T) {
+	l, _ := findLesson("slices")
+	steps, err := lessonSteps(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	in := bufio.NewScanner(strings.NewReader("\n\nb\nq\n"))
+	last, done, err := stepThrough(&out, in, l, steps, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 1 || done {
+		t.Errorf("stopped at step %d (done %v), want 1", last, done)
+	}
+	for _, want := range []string{
+		"[slices 1/7] Slices",
+		"[slices 3/7] allocate a slice uisng - make()",
+		"    s1[0:1] = [1]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
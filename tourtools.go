@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"unsafe"
+)
+
+// The tooling of the lessons: code they call to show what they teach, such
+// as the diagrams of the Slice internals lesson, rather than code that is
+// the subject of a lesson. 'gonutshell coverage' leaves it out.
+
+// sliceViz draws slices of int along with their backing arrays, and
+// remembers the arrays it has seen to point out reallocations.
+type sliceViz struct {
+	w    io.Writer
+	seen map[string]sliceSpan // by slice name
+}
+
+// sliceSpan is the memory a slice can reach: cap elements from ptr.
+type sliceSpan struct {
+	ptr      uintptr
+	len, cap int
+}
+
+func (s sliceSpan) end() uintptr {
+	return s.ptr + uintptr(s.cap)*unsafe.Sizeof(int(0))
+}
+
+func (s sliceSpan) overlaps(t sliceSpan) bool {
+	return s.cap > 0 && t.cap > 0 && s.ptr < t.end() && t.ptr < s.end()
+}
+
+func newSliceViz(w io.Writer) *sliceViz {
+	return &sliceViz{w: w, seen: map[string]sliceSpan{}}
+}
+
+func spanOf(s []int) sliceSpan {
+	return sliceSpan{uintptr(unsafe.Pointer(unsafe.SliceData(s))), len(s), cap(s)}
+}
+
+// show draws the slices ss, named by the fields of names, after the
+// operation op: their headers, then each backing array with the part
+// every slice covers.
+func (v *sliceViz) show(op, names string, ss ...[]int) {
+	ns := strings.Fields(names)
+	fmt.Fprintf(v.w, "\n> %s\n", op)
+	width := 0
+	for _, n := range ns {
+		width = max(width, len(n))
+	}
+	for i, s := range ss {
+		sp := spanOf(s)
+		ptr := fmt.Sprintf("%p", unsafe.SliceData(s))
+		if s == nil {
+			ptr = "nil"
+		}
+		fmt.Fprintf(v.w, "  %-*s  ptr=%s len=%d cap=%d", width, ns[i], ptr, sp.len, sp.cap)
+		if old, ok := v.seen[ns[i]]; ok && old.cap > 0 && !old.overlaps(sp) {
+			fmt.Fprintf(v.w, "  <- new array, cap %d -> %d", old.cap, sp.cap)
+		}
+		fmt.Fprintln(v.w)
+		v.seen[ns[i]] = sp
+	}
+
+	// group the slices by array: slices of one array overlap
+	drawn := make([]bool, len(ss))
+	for i := range ss {
+		if drawn[i] || cap(ss[i]) == 0 {
+			continue
+		}
+		group := []int{i}
+		drawn[i] = true
+		for grew := true; grew; {
+			grew = false
+			for j := range ss {
+				if drawn[j] {
+					continue
+				}
+				for _, k := range group {
+					if spanOf(ss[k]).overlaps(spanOf(ss[j])) {
+						group, drawn[j], grew = append(group, j), true, true
+						break
+					}
+				}
+			}
+		}
+		v.drawArray(ns, ss, group)
+	}
+}
+
+// drawArray draws the part of a backing array the slices of group reach.
+func (v *sliceViz) drawArray(ns []string, ss [][]int, group []int) {
+	// the slices of a group lie within one allocation, which is drawn from
+	// the lowest pointer to the furthest end of their capacities
+	first, end := group[0], spanOf(ss[group[0]]).end()
+	for _, k := range group {
+		sp := spanOf(ss[k])
+		if sp.ptr < spanOf(ss[first]).ptr {
+			first = k
+		}
+		end = max(end, sp.end())
+	}
+	lo := spanOf(ss[first])
+	size := unsafe.Sizeof(int(0))
+	arr := unsafe.Slice(unsafe.SliceData(ss[first]), int((end-lo.ptr)/size))
+
+	cell := 1
+	for _, x := range arr {
+		cell = max(cell, len(strconv.Itoa(x)))
+	}
+	cell += 2
+	width := 0
+	for _, n := range ns {
+		width = max(width, len(n))
+	}
+	pad := strings.Repeat(" ", width+2)
+	fmt.Fprintf(v.w, "  %sarray %p\n", pad, unsafe.SliceData(arr))
+	var index, border, values strings.Builder
+	for i, x := range arr {
+		fmt.Fprintf(&index, " %*d", cell, i)
+		border.WriteString("+" + strings.Repeat("-", cell))
+		fmt.Fprintf(&values, "|%*d ", cell-1, x)
+	}
+	fmt.Fprintf(v.w, "  %s%s\n  %s%s+\n  %s%s|\n  %s%s+\n", pad, index.String(), pad, border.String(), pad, values.String(), pad, border.String())
+	for _, k := range group {
+		sp := spanOf(ss[k])
+		from := int((sp.ptr - lo.ptr) / size)
+		line := strings.Repeat(" ", from*(cell+1)) +
+			strings.Repeat("=", sp.len*(cell+1)) +
+			strings.Repeat(".", (sp.cap-sp.len)*(cell+1))
+		fmt.Fprintf(v.w, "  %-*s  %s\n", width, ns[k], line)
+	}
+	if len(group) > 1 {
+		var names []string
+		for _, k := range group {
+			names = append(names, ns[k])
+		}
+		fmt.Fprintf(v.w, "  %s%s share this array\n", pad, strings.Join(names, " and "))
+	}
+}
+
+// typicalTimings are timings of the lesson's deletes on a laptop, in ns,
+// which the lesson fits in deterministic mode.
+var typicalTimings = [][]float64{
+	{2, 2, 2, 3},
+	{52, 510, 5300, 61000},
+	{55, 530, 5200, 64000},
+}
+
+// complexityFit is how a series of timings grows with the size of the input.
+type complexityFit struct {
+	class string  // "O(1)" or "O(n)"
+	ns    float64 // per operation, or per element for O(n)
+}
+
+func (f complexityFit) String() string {
+	if f.class == "O(n)" {
+		return fmt.Sprintf("%s, about %s per element", f.class, formatNs(f.ns))
+	}
+	return fmt.Sprintf("%s, about %s per delete", f.class, formatNs(f.ns))
+}
+
+// fitComplexity fits timings ns, taken at the given sizes, to t = c and to
+// t = c·n. Both are fitted on a log scale, so that every size counts the
+// same, and the model with the smaller squared error is returned.
+func fitComplexity(sizes []int, ns []float64) complexityFit {
+	// on a log scale c is the mean of log t, or of log t/n
+	var c1, cn float64
+	for i, t := range ns {
+		c1 += math.Log(t)
+		cn += math.Log(t / float64(sizes[i]))
+	}
+	c1 /= float64(len(ns))
+	cn /= float64(len(ns))
+	var e1, en float64
+	for i, t := range ns {
+		e1 += math.Pow(math.Log(t)-c1, 2)
+		en += math.Pow(math.Log(t)-cn-math.Log(float64(sizes[i])), 2)
+	}
+	if en < e1 {
+		return complexityFit{"O(n)", math.Exp(cn)}
+	}
+	return complexityFit{"O(1)", math.Exp(c1)}
+}
+
+// formatNs formats a duration of ns nanoseconds with 3 significant digits.
+func formatNs(ns float64) string {
+	unit := "ns"
+	for _, u := range []string{"µs", "ms", "s"} {
+		if ns < 1000 {
+			break
+		}
+		ns, unit = ns/1000, u
+	}
+	return fmt.Sprintf("%.3g%s", ns, unit)
+}
+
+// printTimings writes the timings as a table with a row per size.
+func printTimings(w io.Writer, idioms []deleteIdiom, sizes []int, timings [][]float64) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "n\t")
+	for _, d := range idioms {
+		fmt.Fprintf(tw, "%s\t", d.name)
+	}
+	fmt.Fprintln(tw)
+	for j, n := range sizes {
+		fmt.Fprintf(tw, "%d\t", n)
+		for i := range idioms {
+			fmt.Fprintf(tw, "%s\t", formatNs(timings[i][j]))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// chartTimings draws the timings as bars on a log scale: every ten '#' are
+// ten times as long.
+func chartTimings(w io.Writer, idioms []deleteIdiom, sizes []int, timings [][]float64) {
+	fmt.Fprintln(w, "time per delete, log scale (10 # = 10x)")
+	for i, d := range idioms {
+		for j, n := range sizes {
+			name := ""
+			if j == 0 {
+				name = d.name
+			}
+			bar := max(1, int(math.Round(10*math.Log10(timings[i][j])))+1)
+			fmt.Fprintf(w, "%-14s n=%-9d %s %s\n", name, n, strings.Repeat("#", bar), formatNs(timings[i][j]))
+		}
+	}
+}
+
+// hammer calls next n times from each of g goroutines at once and returns
+// the largest value the calls returned: g·n if next counts by one from 0
+// and no call was lost.
+func hammer(next func() int, g, n int) int {
+	var wg sync.WaitGroup
+	var largest atomic.Int64
+	for range g {
+		wg.Go(func() {
+			m := 0
+			for range n {
+				m = max(m, next())
+			}
+			for {
+				old := largest.Load()
+				if int64(m) <= old || largest.CompareAndSwap(old, int64(m)) {
+					break
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return int(largest.Load())
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// editFile opens path in the learner's editor and waits for it to be closed.
+var editFile = func(path string) error {
+	editor := cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// stepArg returns the index of the step of steps that arg names, by its
+// number counted from 1 or by its heading.
+func stepArg(l Lesson, steps []step, arg string) (int, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(steps) {
+			return 0, fmt.Errorf("%s has steps 1 to %d", l.ID, len(steps))
+		}
+		return n - 1, nil
+	}
+	if i, ok := findStep(steps, arg); ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("no step %q in %s, see 'gonutshell step %s'", arg, l.ID, l.ID)
+}
+
+func cmdTry(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("try", flag.ContinueOnError)
+	fs.SetOutput(w)
+	allowNetwork := fs.Bool("allow-network", false, "run the edited code with network access where it cannot be taken away from it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) != 2 {
+		return errors.New("usage: gonutshell try [-allow-network] <lesson> <step>")
+	}
+	lim := defaultLimits
+	lim.AllowNetwork = *allowNetwork
+	if err := lim.checkNetwork(); err != nil {
+		return err
+	}
+	l, ok := findLesson(args[0])
+	if !ok {
+		return fmt.Errorf("no lesson %q, see 'gonutshell list'", args[0])
+	}
+	steps, err := lessonSteps(l)
+	if err != nil {
+		return err
+	}
+	i, err := stepArg(l, steps, args[1])
+	if err != nil {
+		return err
+	}
+	s := steps[i]
+	if s.Code == "" {
+		return fmt.Errorf("step %d of %s has no code to try", i+1, l.ID)
+	}
+
+	dir, err := os.MkdirTemp("", "gonutshell-try-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	// the .go suffix gets the snippet highlighted in most editors
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d.go", l.ID, i+1))
+	if err := os.WriteFile(path, []byte(s.Code+"\n"), 0o644); err != nil {
+		return err
+	}
+	return tryStep(w, bufio.NewScanner(stdin), l, s, path, lim)
+}
+
+// tryStep lets the learner edit the code of step s in the file at path and
+// compares the output of the edited code, run within lim, with the
+// original, as many times as the learner wants.
+func tryStep(w io.Writer, in *bufio.Scanner, l Lesson, s step, path string, lim sandboxLimits) error {
+	orig := s.output(captureLesson(l))
+	for {
+		if err := editFile(path); err != nil {
+			return fmt.Errorf("editor: %v", err)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		code := strings.TrimSpace(string(src))
+		if code == strings.TrimSpace(s.Code) {
+			fmt.Fprintln(w, "The snippet is unchanged.")
+		} else {
+			var out strings.Builder
+			err := runEditedStep(context.Background(), l, s, code, lim, &out)
+			var be *buildError
+			if errors.As(err, &be) {
+				fmt.Fprintf(w, "\nThe edited snippet does not compile:\n%s", indent(strings.TrimRight(be.Output, "\n"), "    "))
+				fmt.Fprintln(w)
+			} else {
+				fmt.Fprintln(w)
+				sideBySide(w, outputLines(orig), outputLines(out.String()), "original output", "edited output")
+				if err != nil {
+					fmt.Fprintf(w, "\nThe edited snippet failed: %v\n", err)
+				}
+			}
+		}
+		fmt.Fprint(w, "\n[e]dit again, [Enter] quit > ")
+		if !in.Scan() {
+			fmt.Fprintln(w)
+			return in.Err()
+		}
+		if strings.TrimSpace(in.Text()) != "e" {
+			return nil
+		}
+	}
+}
+
+// sideBySide writes the diff of a and b as two columns in the manner of
+// sdiff: '|' marks lines that changed, '<' lines only in a and '>' lines
+// only in b.
+func sideBySide(w io.Writer, a, b []string, atitle, btitle string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t  %s\n", atitle, btitle)
+	d := diffLines(a, b)
+	for len(d) > 0 {
+		if d[0].op == ' ' {
+			fmt.Fprintf(tw, "%s\t  %s\n", d[0].text, d[0].text)
+			d = d[1:]
+			continue
+		}
+		// pair a run of removed lines with the added lines after it
+		var del, add []string
+		for len(d) > 0 && d[0].op == '-' {
+			del, d = append(del, d[0].text), d[1:]
+		}
+		for len(d) > 0 && d[0].op == '+' {
+			add, d = append(add, d[0].text), d[1:]
+		}
+		for i := range max(len(del), len(add)) {
+			switch {
+			case i >= len(add):
+				fmt.Fprintf(tw, "%s\t<\n", del[i])
+			case i >= len(del):
+				fmt.Fprintf(tw, "\t> %s\n", add[i])
+			default:
+				fmt.Fprintf(tw, "%s\t| %s\n", del[i], add[i])
+			}
+		}
+	}
+	if len(a) == 0 && len(b) == 0 {
+		fmt.Fprintf(tw, "(nothing)\t  (nothing)\n")
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSideBySide(t *testing.T) {
+	var b strings.Builder
+	sideBySide(&b, []string{"a", "b", "c", "d"}, []string{"a", "x", "d", "e"}, "old", "new")
+	want := `old    new
+a      a
+b    | x
+c    <
+d      d
+     > e
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestTryNetwork(t *testing.T) {
+	defer func(f func() bool) { isolatedNetwork = f }(isolatedNetwork)
+	isolatedNetwork = func() bool { return false }
+	if err := cmdTry(io.Discard, []string{"slices", "5"}); err != errNetwork {
+		t.Errorf("try without a network namespace: %v, want %v", err, errNetwork)
+	}
+}
+
+func TestTryStep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds programs with the go command")
+	}
+	defer func(f func(string) error) { editFile = f }(editFile)
+	edits := []string{
+		"o2 := []int{1, 2, 3, 4, 5}\ncopy(o2[0:2], e1[2:])\nfmt.Fprintln(w, \"o2 =\", o2)",
+		"o2 := 1",
+	}
+	editFile = func(path string) error {
+		err := os.WriteFile(path, []byte(edits[0]), 0o644)
+		edits = edits[1:]
+		return err
+	}
+
+	l, _ := findLesson("slices")
+	steps, err := lessonSteps(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := stepArg(l, steps, "copy - with sub-range")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	path := filepath.Join(t.TempDir(), "snippet.go")
+	if err := tryStep(&out, bufio.NewScanner(strings.NewReader("e\n\n")), l, steps[i], path, defaultLimits); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"o2 = [1 10 20 30 5]  | o2 = [30 40 3 4 5]\n",
+		"o2 = [1 30 40 40 5]  <\n",
+		"does not compile:\n    line 1:1: declared and not used: o2\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// typeClaimPatterns match comments that state the type of a variable, as
+// in "Type of 'c' = int32" or "a5 type = [5]int". The first group is the
+// variable and the second one the type, which runs up to a ';'.
+var typeClaimPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Type of (?:[\w-]+ )*'(\w+)' = ([^;]+)`),
+	regexp.MustCompile(`\b(\w+) type = ([^;]+)`),
+}
+
+// typeClaim is a type stated by a comment, with the type the compiler
+// infers for the variable. problem is empty when the two agree.
+type typeClaim struct {
+	pos     token.Position
+	name    string
+	stated  string
+	actual  types.Type
+	problem string
+}
+
+// typeCheck type-checks files as package main. Errors are collected rather
+// than stopping the check, as a single file of the tour refers to names
+// declared in the others.
+func typeCheck(fset *token.FileSet, files []*ast.File) (*types.Package, *types.Info, []error) {
+	var errs []error
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error:    func(err error) { errs = append(errs, err) },
+	}
+	info := &types.Info{
+		Types:  map[ast.Expr]types.TypeAndValue{},
+		Defs:   map[*ast.Ident]types.Object{},
+		Uses:   map[*ast.Ident]types.Object{},
+		Scopes: map[ast.Node]*types.Scope{},
+
+		Instances: map[*ast.Ident]types.Instance{},
+	}
+	pkg, _ := conf.Check("main", fset, files, info)
+	return pkg, info, errs
+}
+
+// checkTypeClaims finds the type claims in the comments of files and
+// compares each with the type of the variable it names.
+func checkTypeClaims(fset *token.FileSet, files []*ast.File) []typeClaim {
+	pkg, info, _ := typeCheck(fset, files)
+	var claims []typeClaim
+	for _, f := range files {
+		for _, g := range f.Comments {
+			for _, c := range g.List {
+				for _, re := range typeClaimPatterns {
+					for _, m := range re.FindAllStringSubmatch(c.Text, -1) {
+						claims = append(claims, checkTypeClaim(fset, pkg, info, c.Pos(), m[1], strings.TrimSpace(m[2])))
+					}
+				}
+			}
+		}
+	}
+	return claims
+}
+
+func checkTypeClaim(fset *token.FileSet, pkg *types.Package, info *types.Info, pos token.Pos, name, stated string) typeClaim {
+	tc := typeClaim{pos: fset.Position(pos), name: name, stated: stated}
+	obj := lookupVar(pkg, info, pos, name)
+	if obj == nil {
+		tc.problem = fmt.Sprintf("no variable '%s' for the claim to refer to", name)
+		return tc
+	}
+	tc.actual = obj.Type()
+	claimed, err := types.Eval(fset, pkg, pos, stated)
+	switch {
+	case err != nil || !claimed.IsType():
+		tc.problem = fmt.Sprintf("'%s' is claimed to be %s, which is not a type", name, stated)
+	case !types.Identical(claimed.Type, tc.actual):
+		tc.problem = fmt.Sprintf("'%s' is claimed to be %s but is %s", name, stated, describeType(tc.actual))
+	}
+	return tc
+}
+
+// lookupVar resolves name as seen from pos. A comment may also describe a
+// variable of another function, e.g. a parameter of a helper whose output
+// it shows, so failing that a variable of that name declared only once in
+// the package is used.
+func lookupVar(pkg *types.Package, info *types.Info, pos token.Pos, name string) types.Object {
+	if s := pkg.Scope().Innermost(pos); s != nil {
+		if _, obj := s.LookupParent(name, pos); obj != nil {
+			if _, ok := obj.(*types.Var); ok {
+				return obj
+			}
+		}
+	}
+	var found types.Object
+	for id, obj := range info.Defs {
+		if _, ok := obj.(*types.Var); !ok || id.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = obj
+	}
+	return found
+}
+
+// describeType prints t, spelling out what byte and rune stand for.
+func describeType(t types.Type) string {
+	if b, ok := t.(*types.Basic); ok && b.Name() != types.Typ[b.Kind()].Name() {
+		return fmt.Sprintf("%s (%s)", b.Name(), types.Typ[b.Kind()].Name())
+	}
+	return types.TypeString(t, nil)
+}
+
+func cmdTypecheck(w io.Writer, args []string) error {
+	t, err := loadTour()
+	if err != nil {
+		return err
+	}
+	claims := checkTypeClaims(t.fset, t.files)
+	wrong := 0
+	for _, c := range claims {
+		if c.problem != "" {
+			wrong++
+			fmt.Fprintf(w, "%s: %s\n", c.pos, c.problem)
+		}
+	}
+	fmt.Fprintf(w, "%d type claims checked, %d wrong\n", len(claims), wrong)
+	if wrong > 0 {
+		return fmt.Errorf("%d type claims do not match the compiler", wrong)
+	}
+	return nil
+}
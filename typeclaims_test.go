@@ -0,0 +1,58 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestTourTypeClaims(t *testing.T) {
+	src, err := loadTour()
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := checkTypeClaims(src.fset, src.files)
+	if len(claims) == 0 {
+		t.Fatal("no type claims found in the tour")
+	}
+	for _, c := range claims {
+		if c.problem != "" {
+			t.Errorf("%s: %s", c.pos, c.problem)
+		}
+	}
+}
+
+func TestTypeClaimMismatch(t *testing.T) {
+	const src = `package main
+
+func main() {
+	c := 'a'
+	// Type of 'c' = uint32
+	a5 := [...]int{1, 2, 3, 4, 5}
+	// a5 type = [5]int
+	b := byte('\n')
+	// Value of 'b' = 10; Type of 'b' = uint8
+	_, _, _ = c, a5, b
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "claims.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := checkTypeClaims(fset, []*ast.File{f})
+	if len(claims) != 3 {
+		t.Fatalf("found %d claims, want 3", len(claims))
+	}
+	want := []string{"'c' is claimed to be uint32 but is rune (int32)", "", ""}
+	for i, c := range claims {
+		if c.problem != want[i] {
+			t.Errorf("%s: problem = %q, want %q", c.pos, c.problem, want[i])
+		}
+	}
+	if !strings.HasSuffix(claims[0].pos.String(), "claims.go:5:2") {
+		t.Errorf("claim reported at %s, want claims.go:5:2", claims[0].pos)
+	}
+}
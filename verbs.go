@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// The Printing lesson shows a handful of verbs on values picked for them.
+// 'gonutshell fmt' formats any value with any verb, and its matrix mode
+// every verb with every flag and width on values of many types, marking
+// the combinations fmt rejects with a %!verb(type=value) result.
+
+// verbPattern matches a single verb with its flags, width and precision.
+var verbPattern = regexp.MustCompile(`^%([-+# 0]*)([0-9]*)(\.[0-9]*)?([a-zA-Z])$`)
+
+// parseVerb returns verb as a format of one verb, adding the '%' it may
+// have been given without, as the shell is less likely to mangle "04d".
+func parseVerb(verb string) (string, error) {
+	if !strings.HasPrefix(verb, "%") {
+		verb = "%" + verb
+	}
+	if !verbPattern.MatchString(verb) {
+		return "", fmt.Errorf("%q is not a verb such as %%d, %%04d or %%-8.2f", verb)
+	}
+	return verb, nil
+}
+
+// sampleTypes are the types a value given on the command line can be
+// read as.
+var sampleTypes = map[string]reflect.Type{
+	"bool":           reflect.TypeFor[bool](),
+	"byte":           reflect.TypeFor[byte](),
+	"complex128":     reflect.TypeFor[complex128](),
+	"float32":        reflect.TypeFor[float32](),
+	"float64":        reflect.TypeFor[float64](),
+	"int":            reflect.TypeFor[int](),
+	"int8":           reflect.TypeFor[int8](),
+	"int16":          reflect.TypeFor[int16](),
+	"int32":          reflect.TypeFor[int32](),
+	"int64":          reflect.TypeFor[int64](),
+	"rune":           reflect.TypeFor[rune](),
+	"string":         reflect.TypeFor[string](),
+	"uint":           reflect.TypeFor[uint](),
+	"uint8":          reflect.TypeFor[uint8](),
+	"uint16":         reflect.TypeFor[uint16](),
+	"uint32":         reflect.TypeFor[uint32](),
+	"uint64":         reflect.TypeFor[uint64](),
+	"uintptr":        reflect.TypeFor[uintptr](),
+	"*int":           reflect.TypeFor[*int](),
+	"[]byte":         reflect.TypeFor[[]byte](),
+	"[]float64":      reflect.TypeFor[[]float64](),
+	"[]int":          reflect.TypeFor[[]int](),
+	"[]string":       reflect.TypeFor[[]string](),
+	"map[string]int": reflect.TypeFor[map[string]int](),
+}
+
+// parseSample reads s as a value of the type named typ. Without a type, s
+// is an int, a float64 or a bool if it reads as one and a string otherwise.
+// Slices and maps are written as in JSON, e.g. [1, 2] or {"a": 1}, except
+// that a []byte is the bytes of s.
+func parseSample(typ, s string) (any, error) {
+	if typ == "" {
+		if i, err := strconv.Atoi(s); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+		return s, nil
+	}
+	t, ok := sampleTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("cannot read a value of type %s, only %s", typ, strings.Join(slices.Sorted(maps.Keys(sampleTypes)), ", "))
+	}
+	v := reflect.New(t).Elem()
+	var err error
+	switch {
+	case typ == "rune" && utf8.RuneCountInString(s) == 1:
+		r, _ := utf8.DecodeRuneInString(s)
+		v.SetInt(int64(r))
+	case typ == "[]byte":
+		v.SetBytes([]byte(s))
+	case t.Kind() == reflect.Pointer:
+		var p any
+		if p, err = parseSample(t.Elem().String(), s); err == nil {
+			v.Set(reflect.New(t.Elem()))
+			v.Elem().Set(reflect.ValueOf(p))
+		}
+	case v.CanInt():
+		var i int64
+		if i, err = strconv.ParseInt(s, 0, t.Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case v.CanUint():
+		var u uint64
+		if u, err = strconv.ParseUint(s, 0, t.Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case v.CanFloat():
+		var f float64
+		if f, err = strconv.ParseFloat(s, t.Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case v.CanComplex():
+		var c complex128
+		if c, err = strconv.ParseComplex(s, t.Bits()); err == nil {
+			v.SetComplex(c)
+		}
+	case t.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case t.Kind() == reflect.String:
+		v.SetString(s)
+	default:
+		err = json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err // without the input, which is in the message already
+		}
+		return nil, fmt.Errorf("cannot read %q as %s: %v", s, typ, err)
+	}
+	return v.Interface(), nil
+}
+
+// badVerbPattern matches what fmt prints for a verb it cannot apply.
+var badVerbPattern = regexp.MustCompile(`%!([a-zA-Z])\(`)
+
+// badVerb returns the verb fmt rejected in s, if any.
+func badVerb(s string) (string, bool) {
+	m := badVerbPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+var (
+	matrixVerbs  = "vTtbcdoOqxXUeEfFgGsp"
+	matrixFlags  = []string{"", "-", "+", "#", " ", "0"}
+	matrixWidths = []string{"", "8", ".2", "8.2"}
+)
+
+// matrixSamples are the values the matrix formats: every kind fmt treats
+// differently.
+func matrixSamples() []any {
+	n := 42
+	return []any{
+		42, -7, uint8(200), 3.14159, float32(2.5), 1 + 2i, true,
+		"go", 'G', []int{1, 2}, []byte("hi"), map[string]int{"a": 1},
+		struct {
+			X int
+			Y string
+		}{1, "a"},
+		&n, nil,
+	}
+}
+
+// matrixRow is a verb applied to a sample value.
+type matrixRow struct {
+	format string
+	value  any
+	result string
+	note   string // why the result is special, if it is
+}
+
+// verbMatrix formats samples with every verb of verbs, with every flag and
+// width. A flag that changes nothing compared with the verb without it is
+// pointed out too.
+func verbMatrix(verbs string, samples []any) []matrixRow {
+	var rows []matrixRow
+	for _, verb := range verbs {
+		for _, width := range matrixWidths {
+			for _, v := range samples {
+				plain := fmt.Sprintf("%"+width+string(verb), v)
+				for _, flag := range matrixFlags {
+					format := "%" + flag + width + string(verb)
+					r := matrixRow{format: format, value: v, result: fmt.Sprintf(format, v)}
+					switch bad, ok := badVerb(r.result); {
+					case ok:
+						r.note = fmt.Sprintf("bad verb: no %%%s for %T", bad, v)
+					case flag != "" && r.result == plain:
+						r.note = "the flag changes nothing"
+					}
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+func cmdFmt(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(w)
+	typ := fs.String("type", "", "read the value as a `type` such as int, float64, string or []int")
+	matrix := fs.Bool("matrix", false, "format sample values of many types with every verb, flag and width")
+	verbs := fs.String("verbs", matrixVerbs, "the `letters` of the verbs of the matrix")
+	bad := fs.Bool("bad", false, "in the matrix, list only the combinations fmt rejects")
+	// the flags may come after the verb and value, as in the usage line
+	var pos []string
+	for {
+		if len(args) > 0 && looksPositional(args[0]) {
+			pos, args = append(pos, args[0]), args[1:]
+			continue
+		}
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	w = lessonWriter(w) // for the addresses of %p in deterministic mode
+
+	if *matrix {
+		if len(pos) > 0 {
+			return errors.New("usage: gonutshell fmt -matrix [-verbs letters] [-bad]")
+		}
+		return printVerbMatrix(w, verbMatrix(*verbs, matrixSamples()), *bad)
+	}
+	if len(pos) != 2 {
+		return errors.New("usage: gonutshell fmt <verb> <value> [-type type]")
+	}
+	verb, err := parseVerb(pos[0])
+	if err != nil {
+		return err
+	}
+	v, err := parseSample(*typ, pos[1])
+	if err != nil {
+		return err
+	}
+	out := fmt.Sprintf(verb, v)
+	fmt.Fprintf(w, "fmt.Sprintf(%q, %#v) = %q\n", verb, v, out)
+	if bad, ok := badVerb(out); ok {
+		fmt.Fprintf(w, "%%%s does not apply to a %T; see 'gonutshell fmt -matrix -verbs %s' for what it does apply to\n", bad, v, bad)
+	}
+	return nil
+}
+
+// looksPositional reports whether arg is a verb or a value rather than a
+// flag: it does not start with '-', or it is a negative number or a verb
+// such as -8d, which none of the flags of the fmt command look like.
+func looksPositional(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return true
+	}
+	if _, err := strconv.ParseInt(arg, 0, 64); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseComplex(arg, 128); err == nil {
+		return true
+	}
+	_, err := parseVerb(arg)
+	return err == nil
+}
+
+// printVerbMatrix writes rows as a table, those with a bad verb only if bad
+// is set.
+func printVerbMatrix(w io.Writer, rows []matrixRow, bad bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "format\ttype\tvalue\tresult")
+	nbad := 0
+	for _, r := range rows {
+		_, isBad := badVerb(r.result)
+		if isBad {
+			nbad++
+		} else if bad {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%T\t%s\t%q", r.format, r.value, sampleString(r.value), r.result)
+		if r.note != "" {
+			fmt.Fprintf(tw, "\t%s", r.note)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d combinations, %d of them rejected by fmt.\n", len(rows), nbad)
+	return nil
+}
+
+// sampleString writes a sample value as Go code, short of its type, which
+// has a column of its own.
+func sampleString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case *int:
+		return fmt.Sprintf("&%d", *v)
+	case rune:
+		return strconv.QuoteRune(v)
+	case []byte:
+		return fmt.Sprintf("[]byte(%q)", v)
+	case uint8:
+		return strconv.Itoa(int(v)) // rather than 0xc8
+	}
+	s := fmt.Sprintf("%#v", v)
+	if t := fmt.Sprintf("%T", v); strings.HasPrefix(s, t) {
+		s = s[len(t):]
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseSample(t *testing.T) {
+	for _, tt := range []struct {
+		typ, s string
+		want   string // %T %#v of the value, or the error
+	}{
+		{"", "42", "int 42"},
+		{"", "3.5", "float64 3.5"},
+		{"", "true", "bool true"},
+		{"", "hello", `string "hello"`},
+		{"int8", "0x7f", "int8 127"},
+		{"int8", "128", `cannot read "128" as int8: value out of range`},
+		{"uint16", "65535", "uint16 0xffff"},
+		{"rune", "G", "int32 71"},
+		{"rune", "71", "int32 71"},
+		{"complex128", "1+2i", "complex128 (1+2i)"},
+		{"[]int", "[1, 2]", "[]int []int{1, 2}"},
+		{"[]byte", "hi", "[]uint8 []byte{0x68, 0x69}"},
+		{"map[string]int", `{"a": 1}`, `map[string]int map[string]int{"a":1}`},
+		{"[]int", "1, 2", `cannot read "1, 2" as []int: invalid character ',' after top-level value`},
+		{"chan int", "1", "cannot read a value of type chan int, only *int, []byte,"},
+	} {
+		v, err := parseSample(tt.typ, tt.s)
+		got := fmt.Sprintf("%T %#v", v, v)
+		if err != nil {
+			got = err.Error()
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("parseSample(%q, %q) = %s, want %s", tt.typ, tt.s, got, tt.want)
+		}
+	}
+	v, err := parseSample("*int", "7")
+	if p, ok := v.(*int); err != nil || !ok || *p != 7 {
+		t.Errorf("parseSample(*int, 7) = %#v, %v", v, err)
+	}
+}
+
+func TestVerbMatrix(t *testing.T) {
+	samples := []any{42, "go"}
+	rows := verbMatrix("dq", samples)
+	if want := 2 * len(matrixWidths) * len(samples) * len(matrixFlags); len(rows) != want {
+		t.Fatalf("%d rows, want %d", len(rows), want)
+	}
+	notes := map[string]string{}
+	for _, r := range rows {
+		notes[fmt.Sprintf("%s %v", r.format, r.value)] = r.result + " " + r.note
+	}
+	for row, want := range map[string]string{
+		"%d 42":     `42 `,
+		"%+d 42":    `+42 `,
+		"%#d 42":    `42 the flag changes nothing`,
+		"%-8d 42":   `42       `,
+		"%d go":     `%!d(string=go) bad verb: no %d for string`,
+		"%+q go":    `"go" the flag changes nothing`,
+		"%#q go":    "`go` ",
+		"%8.2q 42":  `     '*' `,
+		"%08d 42":   `00000042 `,
+		"%08.2d 42": `      42 the flag changes nothing`, // a precision turns off padding with zeros
+	} {
+		if got := notes[row]; got != want {
+			t.Errorf("%s gives %q, want %q", row, got, want)
+		}
+	}
+}
+
+func TestCmdFmt(t *testing.T) {
+	for args, want := range map[string]string{
+		"%04d 43":                 "fmt.Sprintf(\"%04d\", 43) = \"0043\"\n",
+		"x 255 -type uint8":       "fmt.Sprintf(\"%x\", 0xff) = \"ff\"\n",
+		"-type string %d 12":      "fmt.Sprintf(\"%d\", \"12\") = \"%!d(string=12)\"\n%d does not apply to a string",
+		"%d -5":                   "fmt.Sprintf(\"%d\", -5) = \"-5\"\n",
+		"-8d -5 -type int8":       "fmt.Sprintf(\"%-8d\", -5) = \"-5      \"\n",
+		"-type float64 .1f -2.25": "fmt.Sprintf(\"%.1f\", -2.25) = \"-2.2\"\n",
+	} {
+		var b strings.Builder
+		if err := cmdFmt(&b, strings.Fields(args)); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(b.String(), want) {
+			t.Errorf("fmt %s printed\n%s\nwant\n%s", args, b.String(), want)
+		}
+	}
+	for _, args := range []string{"%04 43", "%d", "-matrix %d", "%d -5 -tpye int"} {
+		if err := cmdFmt(new(strings.Builder), strings.Fields(args)); err == nil {
+			t.Errorf("fmt %s did not fail", args)
+		}
+	}
+}
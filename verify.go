@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// outputMarker starts a comment that states what a lesson prints.
+const outputMarker = "Output:"
+
+// annotation is an '// Output: ...' comment of a lesson.
+type annotation struct {
+	pos  token.Position
+	want string
+}
+
+// mismatch is an annotation that the real output does not agree with.
+type mismatch struct {
+	annotation
+	got string
+}
+
+// lessonAnnotations returns the '// Output:' comments inside fd, in order.
+func lessonAnnotations(t *tourSource, f *ast.File, fd *ast.FuncDecl) []annotation {
+	var as []annotation
+	for _, g := range f.Comments {
+		if g.Pos() < fd.Pos() || g.End() > fd.End() {
+			continue
+		}
+		for _, c := range g.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			if !strings.HasPrefix(c.Text, "//") || !strings.HasPrefix(text, outputMarker) {
+				continue
+			}
+			as = append(as, annotation{
+				pos:  t.fset.Position(c.Pos()),
+				want: strings.TrimSpace(strings.TrimPrefix(text, outputMarker)),
+			})
+		}
+	}
+	return as
+}
+
+// verifyLesson runs l and checks each of its annotations against the output
+// written since the previous annotation, up to and including the line of
+// the annotation itself. It returns the number of annotations checked.
+func verifyLesson(l Lesson) (int, []mismatch, error) {
+	if l.pack != nil {
+		return 0, nil, nil // the snippets of packs are not annotated
+	}
+	t, err := loadTour()
+	if err != nil {
+		return 0, nil, err
+	}
+	fd, f, err := t.lessonDecl(l)
+	if err != nil {
+		return 0, nil, err
+	}
+	as := lessonAnnotations(t, f, fd)
+	out := captureLesson(l)
+	var bad []mismatch
+	from := t.line(fd.Pos())
+	for _, a := range as {
+		got := out.between(from, a.pos.Line)
+		if !matchLines(got, a.want) {
+			bad = append(bad, mismatch{annotation: a, got: got})
+		}
+		from = a.pos.Line
+	}
+	return len(as), bad, nil
+}
+
+// matchLines reports whether want is the whole of one or more consecutive
+// lines of got, white space aside: "1 2" matches a line "1  2" but not a
+// line "11 22" or "x: 1 2".
+func matchLines(got, want string) bool {
+	want = collapseSpace(want)
+	var lines []string
+	for _, l := range strings.Split(got, "\n") {
+		if l := collapseSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	for i := range lines {
+		for j := i + 1; j <= len(lines); j++ {
+			if strings.Join(lines[i:j], " ") == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collapseSpace trims s and turns every run of white space into one blank,
+// so that annotations need not spell out trailing blanks and newlines.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func cmdVerify(w io.Writer, args []string) error {
+	sel, err := selectLessons(args)
+	if err != nil {
+		return err
+	}
+	checked, failed := 0, 0
+	for _, l := range sel {
+		n, bad, err := verifyLesson(l)
+		if err != nil {
+			return err
+		}
+		checked += n
+		failed += len(bad)
+		for _, m := range bad {
+			fmt.Fprintf(w, "%s: %s: output does not match annotation\n", m.pos, l.ID)
+			fmt.Fprintf(w, "\twant: %s\n", m.want)
+			fmt.Fprintf(w, "\tgot:  %q\n", m.got)
+		}
+	}
+	fmt.Fprintf(w, "%d annotations checked in %d lessons, %d mismatches\n", checked, len(sel), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d annotations do not match the output", failed)
+	}
+	return nil
+}
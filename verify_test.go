@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestLessonAnnotations(t *testing.T) {
+	quickBenchmarks(t)
+	for _, l := range lessons {
+		n, bad, err := verifyLesson(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range bad {
+			t.Errorf("%s: %s: want %q in %q", m.pos, l.ID, m.want, m.got)
+		}
+		t.Logf("%s: %d annotations", l.ID, n)
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	for _, tt := range []struct {
+		got, want string
+		ok        bool
+	}{
+		{"1 2\n", "1 2", true},
+		{"1  2\n", "1 2", true},
+		{"11 22\n", "1 2", false},
+		{"x: 1 2\n", "1 2", false},
+		{"a\nb\nc\n", "b c", true},
+		{"a\nb\nc\n", "a c", false},
+		{"", "", false},
+	} {
+		if ok := matchLines(tt.got, tt.want); ok != tt.ok {
+			t.Errorf("matchLines(%q, %q) = %v, want %v", tt.got, tt.want, ok, tt.ok)
+		}
+	}
+}